@@ -0,0 +1,74 @@
+// Package discover enumerates JVM processes on the local host that are
+// reachable via the Dynamic Attach mechanism, so callers can build
+// fleet-wide tooling (bulk thread dumps, cluster-wide flag audits) on top
+// of jattach.AttachAll without hand-rolling process discovery themselves.
+package discover
+
+import (
+	"path"
+	"strings"
+)
+
+// JVMProcess describes one JVM process found by Discover.
+type JVMProcess struct {
+	PID int
+
+	// Cmdline is the process's full command line, as found in
+	// /proc/<pid>/cmdline (Linux) or reported by ps (macOS). On Windows it
+	// holds the executable's full path instead.
+	Cmdline string
+
+	// MainClass is the JVM's main class or jar, parsed out of Cmdline; the
+	// sun.java.command system property is not consulted. Empty if it could
+	// not be determined, and always empty on Windows.
+	MainClass string
+
+	// User is the name (or, failing that, numeric ID) of the user the
+	// process runs as. Empty if it could not be determined.
+	User string
+
+	// ContainerID is the containing Docker/containerd/CRI container ID,
+	// parsed from /proc/<pid>/cgroup. Empty outside a container, or on
+	// platforms without a cgroup concept.
+	ContainerID string
+}
+
+// Discover scans the host for running JVM processes attachable via
+// jattach. Processes the caller doesn't have permission to inspect are
+// silently skipped rather than failing the whole scan.
+func Discover() ([]JVMProcess, error) {
+	return discover()
+}
+
+// looksLikeJVM reports whether executable (a full path or a bare process
+// name) names a java launcher binary. executable may use either '/' or '\'
+// as its path separator regardless of the host OS (discover_windows.go
+// reports Windows-style paths), and the comparison ignores a trailing
+// ".exe" case-insensitively.
+func looksLikeJVM(executable string) bool {
+	base := path.Base(strings.ReplaceAll(executable, `\`, "/"))
+	base = strings.TrimSuffix(strings.ToLower(base), ".exe")
+	return base == "java"
+}
+
+// parseMainClass extracts the main class or jar name from a JVM command
+// line, skipping JVM flags and the value that follows -cp, -classpath or
+// --class-path. Any other flag is assumed to take no separate value.
+func parseMainClass(args []string) string {
+	for i := 1; i < len(args); i++ {
+		switch {
+		case args[i] == "-jar":
+			if i+1 < len(args) {
+				return args[i+1]
+			}
+			return ""
+		case args[i] == "-cp" || args[i] == "-classpath" || args[i] == "--class-path":
+			i++
+		case strings.HasPrefix(args[i], "-"):
+			// other flag; best effort, assume it takes no separate value
+		default:
+			return args[i]
+		}
+	}
+	return ""
+}
@@ -0,0 +1,108 @@
+//go:build darwin && (amd64 || arm64)
+// +build darwin
+// +build amd64 arm64
+
+package discover
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// discover finds attachable JVMs via the .java_pid<pid> marker sockets the
+// HotSpot attach listener creates in the temp directory, falling back to a
+// ps scan for any JVM that hasn't created its socket yet (it's created
+// lazily, on first attach attempt).
+func discover() ([]JVMProcess, error) {
+	pids := make(map[int]struct{})
+
+	for _, pid := range javaPidSockets() {
+		pids[pid] = struct{}{}
+	}
+	for _, pid := range psJavaPids() {
+		pids[pid] = struct{}{}
+	}
+
+	var procs []JVMProcess
+	for pid := range pids {
+		cmdline, user := psInfo(pid)
+		if cmdline == "" {
+			continue
+		}
+		args := strings.Fields(cmdline)
+		procs = append(procs, JVMProcess{
+			PID:       pid,
+			Cmdline:   cmdline,
+			MainClass: parseMainClass(args),
+			User:      user,
+		})
+	}
+	return procs, nil
+}
+
+func javaPidSockets() []int {
+	dir := tmpDir()
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil
+	}
+
+	var pids []int
+	for _, entry := range entries {
+		name := entry.Name()
+		if !strings.HasPrefix(name, ".java_pid") {
+			continue
+		}
+		if pid, err := strconv.Atoi(strings.TrimPrefix(name, ".java_pid")); err == nil {
+			pids = append(pids, pid)
+		}
+	}
+	return pids
+}
+
+func tmpDir() string {
+	if dir := os.Getenv("TMPDIR"); dir != "" {
+		return filepath.Clean(dir)
+	}
+	return "/tmp"
+}
+
+// psJavaPids falls back to scanning the process table for java processes
+// that haven't created their attach socket yet.
+func psJavaPids() []int {
+	out, err := exec.Command("ps", "-axo", "pid,comm").Output()
+	if err != nil {
+		return nil
+	}
+
+	var pids []int
+	for _, line := range strings.Split(string(out), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			continue
+		}
+		if !looksLikeJVM(fields[1]) {
+			continue
+		}
+		if pid, err := strconv.Atoi(fields[0]); err == nil {
+			pids = append(pids, pid)
+		}
+	}
+	return pids
+}
+
+// psInfo returns pid's full command line and owning username via ps.
+func psInfo(pid int) (cmdline, user string) {
+	out, err := exec.Command("ps", "-o", "user=,command=", "-p", strconv.Itoa(pid)).Output()
+	if err != nil {
+		return "", ""
+	}
+	fields := strings.SplitN(strings.TrimSpace(string(out)), " ", 2)
+	if len(fields) != 2 {
+		return "", ""
+	}
+	return strings.TrimSpace(fields[1]), fields[0]
+}
@@ -0,0 +1,127 @@
+//go:build linux && (amd64 || arm64)
+// +build linux
+// +build amd64 arm64
+
+package discover
+
+import (
+	"os"
+	"os/user"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// discover scans /proc for processes whose executable looks like a JVM.
+func discover() ([]JVMProcess, error) {
+	entries, err := os.ReadDir("/proc")
+	if err != nil {
+		return nil, err
+	}
+
+	var procs []JVMProcess
+	for _, entry := range entries {
+		pid, err := strconv.Atoi(entry.Name())
+		if err != nil {
+			continue
+		}
+
+		args, err := readCmdline(pid)
+		if err != nil || len(args) == 0 || !looksLikeJVM(args[0]) {
+			continue
+		}
+
+		procs = append(procs, JVMProcess{
+			PID:         pid,
+			Cmdline:     strings.Join(args, " "),
+			MainClass:   parseMainClass(args),
+			User:        processUser(pid),
+			ContainerID: containerID(pid),
+		})
+	}
+	return procs, nil
+}
+
+func readCmdline(pid int) ([]string, error) {
+	raw, err := os.ReadFile(filepath.Join("/proc", strconv.Itoa(pid), "cmdline"))
+	if err != nil {
+		return nil, err
+	}
+	raw = bytesTrimTrailingNul(raw)
+	if len(raw) == 0 {
+		return nil, nil
+	}
+	return strings.Split(string(raw), "\x00"), nil
+}
+
+func bytesTrimTrailingNul(b []byte) []byte {
+	for len(b) > 0 && b[len(b)-1] == 0 {
+		b = b[:len(b)-1]
+	}
+	return b
+}
+
+// processUser resolves the owning username of pid from /proc/<pid>/status,
+// falling back to the numeric UID if the name can't be looked up.
+func processUser(pid int) string {
+	data, err := os.ReadFile(filepath.Join("/proc", strconv.Itoa(pid), "status"))
+	if err != nil {
+		return ""
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		if !strings.HasPrefix(line, "Uid:") {
+			continue
+		}
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			return ""
+		}
+		uid := fields[1]
+		if u, err := user.LookupId(uid); err == nil {
+			return u.Username
+		}
+		return uid
+	}
+	return ""
+}
+
+// containerID extracts the container ID from pid's cgroup membership, e.g.
+// "0::/docker/<id>" or ".../kubepods/.../crio-<id>.scope".
+func containerID(pid int) string {
+	data, err := os.ReadFile(filepath.Join("/proc", strconv.Itoa(pid), "cgroup"))
+	if err != nil {
+		return ""
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		path := line
+		if idx := strings.Index(line, ":"); idx >= 0 {
+			if idx2 := strings.Index(line[idx+1:], ":"); idx2 >= 0 {
+				path = line[idx+1+idx2+1:]
+			}
+		}
+		if id := extractContainerID(path); id != "" {
+			return id
+		}
+	}
+	return ""
+}
+
+func extractContainerID(cgroupPath string) string {
+	base := filepath.Base(strings.TrimSuffix(cgroupPath, ".scope"))
+	if idx := strings.LastIndexByte(base, '-'); idx >= 0 {
+		base = base[idx+1:]
+	}
+	if len(base) >= 12 && isHex(base) {
+		return base
+	}
+	return ""
+}
+
+func isHex(s string) bool {
+	for _, r := range s {
+		if !((r >= '0' && r <= '9') || (r >= 'a' && r <= 'f') || (r >= 'A' && r <= 'F')) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,42 @@
+package discover
+
+import "testing"
+
+func TestParseMainClass(t *testing.T) {
+	cases := []struct {
+		name string
+		args []string
+		want string
+	}{
+		{"jar", []string{"java", "-Xmx1g", "-jar", "app.jar"}, "app.jar"},
+		{"classpath", []string{"java", "-cp", "lib/*.jar", "com.example.Main"}, "com.example.Main"},
+		{"no flags", []string{"java", "com.example.Main", "arg1"}, "com.example.Main"},
+		{"jar with no value", []string{"java", "-jar"}, ""},
+		{"only flags", []string{"java", "-Xmx1g"}, ""},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := parseMainClass(tc.args); got != tc.want {
+				t.Errorf("parseMainClass(%v) = %q, want %q", tc.args, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestLooksLikeJVM(t *testing.T) {
+	cases := []struct {
+		executable string
+		want       bool
+	}{
+		{"java", true},
+		{"/usr/bin/java", true},
+		{"C:\\Program Files\\Java\\bin\\java.exe", true},
+		{"JAVA.EXE", true},
+		{"node", false},
+	}
+	for _, tc := range cases {
+		if got := looksLikeJVM(tc.executable); got != tc.want {
+			t.Errorf("looksLikeJVM(%q) = %v, want %v", tc.executable, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,97 @@
+//go:build windows && (amd64 || arm64 || 386)
+// +build windows
+// +build amd64 arm64 386
+
+package discover
+
+import (
+	"golang.org/x/sys/windows"
+)
+
+// discover enumerates running processes via EnumProcesses and keeps the
+// ones whose image is a java launcher. Windows has no cmdline-by-PID API
+// without WMI or NtQueryInformationProcess, so MainClass is left empty
+// here; Cmdline holds the resolved executable path instead.
+func discover() ([]JVMProcess, error) {
+	pids, err := enumProcessIDs()
+	if err != nil {
+		return nil, err
+	}
+
+	var procs []JVMProcess
+	for _, pid := range pids {
+		if pid == 0 {
+			continue
+		}
+		exe, err := processExecutable(pid)
+		if err != nil || !looksLikeJVM(exe) {
+			continue
+		}
+		procs = append(procs, JVMProcess{
+			PID:     int(pid),
+			Cmdline: exe,
+			User:    processUser(pid),
+		})
+	}
+	return procs, nil
+}
+
+// enumProcessIDs wraps psapi's EnumProcesses, growing the buffer until it's
+// clearly larger than the live process count.
+func enumProcessIDs() ([]uint32, error) {
+	pids := make([]uint32, 1024)
+	for {
+		var bytesReturned uint32
+		if err := windows.EnumProcesses(pids, &bytesReturned); err != nil {
+			return nil, err
+		}
+		n := int(bytesReturned) / 4
+		if n < len(pids) {
+			return pids[:n], nil
+		}
+		pids = make([]uint32, len(pids)*2)
+	}
+}
+
+func processExecutable(pid uint32) (string, error) {
+	h, err := windows.OpenProcess(windows.PROCESS_QUERY_LIMITED_INFORMATION, false, pid)
+	if err != nil {
+		return "", err
+	}
+	defer windows.CloseHandle(h)
+
+	buf := make([]uint16, windows.MAX_PATH)
+	size := uint32(len(buf))
+	if err := windows.QueryFullProcessImageName(h, 0, &buf[0], &size); err != nil {
+		return "", err
+	}
+	return windows.UTF16ToString(buf[:size]), nil
+}
+
+// processUser resolves pid's owner as DOMAIN\User, returning "" if the
+// process can't be opened or the token's SID can't be looked up (e.g. a
+// privileged system process).
+func processUser(pid uint32) string {
+	h, err := windows.OpenProcess(windows.PROCESS_QUERY_LIMITED_INFORMATION, false, pid)
+	if err != nil {
+		return ""
+	}
+	defer windows.CloseHandle(h)
+
+	var token windows.Token
+	if err := windows.OpenProcessToken(h, windows.TOKEN_QUERY, &token); err != nil {
+		return ""
+	}
+	defer token.Close()
+
+	tokenUser, err := token.GetTokenUser()
+	if err != nil {
+		return ""
+	}
+
+	account, domain, _, err := tokenUser.User.Sid.LookupAccount("")
+	if err != nil {
+		return ""
+	}
+	return domain + `\` + account
+}
@@ -1,12 +1,14 @@
-//go:build (linux || darwin || windows) && (amd64 || arm64 || 386)
-// +build linux darwin windows
-// +build amd64 arm64 386
+//go:build (windows && (amd64 || arm64 || 386)) || ((linux || darwin) && (amd64 || arm64))
 
 package jattach_test
 
 import (
+	"context"
 	"fmt"
+	"io"
 	"log"
+	"os"
+	"time"
 
 	jattach "github.com/vlsi/jattach/v2"
 )
@@ -117,3 +119,47 @@ func ExampleAttachWithOutput() {
 
 	fmt.Println(output)
 }
+
+// ExampleAttachContext demonstrates streaming a large thread dump straight
+// to a file, with a deadline in case the JVM is too busy to respond.
+func ExampleAttachContext() {
+	pid := 12345 // Replace with actual JVM PID
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	f, err := os.Create("threaddump.txt")
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer f.Close()
+
+	exitCode, err := jattach.AttachContext(ctx, pid, f, jattach.ThreadDump)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if exitCode != 0 {
+		fmt.Printf("Command failed with exit code %d\n", exitCode)
+	}
+}
+
+// ExampleAttachReader demonstrates pulling a command's response as a plain
+// io.Reader instead of pushing it into a writer.
+func ExampleAttachReader() {
+	pid := 12345 // Replace with actual JVM PID
+
+	r, exitCode, err := jattach.AttachReader(context.Background(), pid, jattach.InspectHeap)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer r.Close()
+
+	if _, err := io.Copy(os.Stdout, r); err != nil {
+		log.Fatal(err)
+	}
+
+	if *exitCode != 0 {
+		fmt.Printf("Command failed with exit code %d\n", *exitCode)
+	}
+}
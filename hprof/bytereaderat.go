@@ -0,0 +1,27 @@
+package hprof
+
+import "io"
+
+// byteReaderAt adapts a memory-mapped (or fully-read) byte slice to
+// io.ReaderAt, so Walk can treat a mapped file exactly like any other
+// random-access source.
+type byteReaderAt struct {
+	data []byte
+}
+
+var _ io.ReaderAt = (*byteReaderAt)(nil)
+
+func newByteReaderAt(data []byte) *byteReaderAt {
+	return &byteReaderAt{data: data}
+}
+
+func (r *byteReaderAt) ReadAt(p []byte, off int64) (int, error) {
+	if off < 0 || off >= int64(len(r.data)) {
+		return 0, io.EOF
+	}
+	n := copy(p, r.data[off:])
+	if n < len(p) {
+		return n, io.EOF
+	}
+	return n, nil
+}
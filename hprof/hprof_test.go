@@ -0,0 +1,113 @@
+package hprof
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+)
+
+// buildTestDump assembles a minimal, valid HPROF byte stream: a header, one
+// STRING record naming a class, one LOAD_CLASS record, and a HEAP_DUMP
+// containing a single zero-field instance of that class.
+func buildTestDump(t *testing.T) []byte {
+	t.Helper()
+	const idSize = 8
+
+	var buf bytes.Buffer
+	buf.WriteString(magic)
+	buf.WriteByte(0)
+	mustWrite(t, &buf, int32(idSize))
+	mustWrite(t, &buf, uint32(0)) // timestamp high
+	mustWrite(t, &buf, uint32(0)) // timestamp low
+
+	writeRecord := func(tag Tag, body []byte) {
+		buf.WriteByte(byte(tag))
+		mustWrite(t, &buf, uint32(0)) // microseconds
+		mustWrite(t, &buf, uint32(len(body)))
+		buf.Write(body)
+	}
+
+	var stringBody bytes.Buffer
+	mustWrite(t, &stringBody, uint64(0x1))
+	stringBody.WriteString("com.example.Foo")
+	writeRecord(TagString, stringBody.Bytes())
+
+	var loadClassBody bytes.Buffer
+	mustWrite(t, &loadClassBody, uint32(1))   // class serial
+	mustWrite(t, &loadClassBody, uint64(0x2)) // class object ID
+	mustWrite(t, &loadClassBody, uint32(0))   // stack trace serial
+	mustWrite(t, &loadClassBody, uint64(0x1)) // class name string ID
+	writeRecord(TagLoadClass, loadClassBody.Bytes())
+
+	var heapDump bytes.Buffer
+	heapDump.WriteByte(byte(SubTagClassDump))
+	mustWrite(t, &heapDump, uint64(0x2)) // class object ID
+	mustWrite(t, &heapDump, uint32(0))   // stack trace serial
+	mustWrite(t, &heapDump, uint64(0))   // superclass
+	mustWrite(t, &heapDump, uint64(0))   // classloader
+	mustWrite(t, &heapDump, uint64(0))   // signers
+	mustWrite(t, &heapDump, uint64(0))   // protection domain
+	mustWrite(t, &heapDump, uint64(0))   // reserved
+	mustWrite(t, &heapDump, uint64(0))   // reserved
+	mustWrite(t, &heapDump, uint32(16))  // instance size
+	mustWrite(t, &heapDump, uint16(0))   // constant pool size
+	mustWrite(t, &heapDump, uint16(0))   // static field count
+	mustWrite(t, &heapDump, uint16(0))   // instance field count
+
+	heapDump.WriteByte(byte(SubTagInstanceDump))
+	mustWrite(t, &heapDump, uint64(0x100)) // object ID
+	mustWrite(t, &heapDump, uint32(0))     // stack trace serial
+	mustWrite(t, &heapDump, uint64(0x2))   // class object ID
+	mustWrite(t, &heapDump, uint32(0))     // 0 bytes of field data
+	writeRecord(TagHeapDump, heapDump.Bytes())
+
+	return buf.Bytes()
+}
+
+func mustWrite(t *testing.T, buf *bytes.Buffer, v interface{}) {
+	t.Helper()
+	if err := binary.Write(buf, binary.BigEndian, v); err != nil {
+		t.Fatalf("failed to write test fixture: %v", err)
+	}
+}
+
+type recordingVisitor struct {
+	NopVisitor
+	strings   []StringRecord
+	classes   []LoadClass
+	instances []InstanceDump
+}
+
+func (v *recordingVisitor) OnString(s StringRecord) error {
+	v.strings = append(v.strings, s)
+	return nil
+}
+
+func (v *recordingVisitor) OnLoadClass(lc LoadClass) error {
+	v.classes = append(v.classes, lc)
+	return nil
+}
+
+func (v *recordingVisitor) OnInstanceDump(in InstanceDump) error {
+	v.instances = append(v.instances, in)
+	return nil
+}
+
+func TestWalk(t *testing.T) {
+	data := buildTestDump(t)
+
+	var v recordingVisitor
+	if err := Walk(bytes.NewReader(data), &v); err != nil {
+		t.Fatalf("Walk failed: %v", err)
+	}
+
+	if len(v.strings) != 1 || v.strings[0].Text != "com.example.Foo" {
+		t.Errorf("unexpected strings: %+v", v.strings)
+	}
+	if len(v.classes) != 1 || v.classes[0].ClassObjectID != 0x2 {
+		t.Errorf("unexpected classes: %+v", v.classes)
+	}
+	if len(v.instances) != 1 || v.instances[0].ObjectID != 0x100 {
+		t.Errorf("unexpected instances: %+v", v.instances)
+	}
+}
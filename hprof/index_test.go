@@ -0,0 +1,134 @@
+package hprof
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// buildRefTestDump assembles an HPROF dump with one class ("com.example.Foo",
+// instance size 16 plus one 8-byte object-reference field) and two
+// instances: objA holds the only reference to objB.
+func buildRefTestDump(t *testing.T) []byte {
+	t.Helper()
+	const idSize = 8
+	const classID = 0x2
+	const objA = 0x100
+	const objB = 0x200
+
+	var buf bytes.Buffer
+	buf.WriteString(magic)
+	buf.WriteByte(0)
+	mustWrite(t, &buf, int32(idSize))
+	mustWrite(t, &buf, uint32(0))
+	mustWrite(t, &buf, uint32(0))
+
+	writeRecord := func(tag Tag, body []byte) {
+		buf.WriteByte(byte(tag))
+		mustWrite(t, &buf, uint32(0))
+		mustWrite(t, &buf, uint32(len(body)))
+		buf.Write(body)
+	}
+
+	var stringBody bytes.Buffer
+	mustWrite(t, &stringBody, uint64(0x1))
+	stringBody.WriteString("com.example.Foo")
+	writeRecord(TagString, stringBody.Bytes())
+
+	var loadClassBody bytes.Buffer
+	mustWrite(t, &loadClassBody, uint32(1))
+	mustWrite(t, &loadClassBody, uint64(classID))
+	mustWrite(t, &loadClassBody, uint32(0))
+	mustWrite(t, &loadClassBody, uint64(0x1))
+	writeRecord(TagLoadClass, loadClassBody.Bytes())
+
+	var heapDump bytes.Buffer
+	heapDump.WriteByte(byte(SubTagClassDump))
+	mustWrite(t, &heapDump, uint64(classID))
+	mustWrite(t, &heapDump, uint32(0))
+	mustWrite(t, &heapDump, uint64(0)) // superclass
+	mustWrite(t, &heapDump, uint64(0)) // classloader
+	mustWrite(t, &heapDump, uint64(0)) // signers
+	mustWrite(t, &heapDump, uint64(0)) // protection domain
+	mustWrite(t, &heapDump, uint64(0)) // reserved
+	mustWrite(t, &heapDump, uint64(0)) // reserved
+	mustWrite(t, &heapDump, uint32(16))
+	mustWrite(t, &heapDump, uint16(0))         // constant pool size
+	mustWrite(t, &heapDump, uint16(0))         // static field count
+	mustWrite(t, &heapDump, uint16(1))         // instance field count
+	mustWrite(t, &heapDump, uint64(0x3))       // field name ID
+	mustWrite(t, &heapDump, uint8(TypeObject)) // field type
+
+	heapDump.WriteByte(byte(SubTagInstanceDump))
+	mustWrite(t, &heapDump, uint64(objA))
+	mustWrite(t, &heapDump, uint32(0))
+	mustWrite(t, &heapDump, uint64(classID))
+	mustWrite(t, &heapDump, uint32(idSize)) // field bytes length
+	mustWrite(t, &heapDump, uint64(objB))   // ref field value
+
+	heapDump.WriteByte(byte(SubTagInstanceDump))
+	mustWrite(t, &heapDump, uint64(objB))
+	mustWrite(t, &heapDump, uint32(0))
+	mustWrite(t, &heapDump, uint64(classID))
+	mustWrite(t, &heapDump, uint32(idSize)) // field bytes length
+	mustWrite(t, &heapDump, uint64(0))      // no reference
+
+	writeRecord(TagHeapDump, heapDump.Bytes())
+
+	return buf.Bytes()
+}
+
+func writeTestDumpFile(t *testing.T, data []byte) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "test.hprof")
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatalf("failed to write test dump: %v", err)
+	}
+	return path
+}
+
+func TestBuildIndex_ClassHistogram(t *testing.T) {
+	path := writeTestDumpFile(t, buildRefTestDump(t))
+
+	idx, err := BuildIndex(path)
+	if err != nil {
+		t.Fatalf("BuildIndex failed: %v", err)
+	}
+
+	stats, ok := idx.ClassHistogram["com.example.Foo"]
+	if !ok {
+		t.Fatalf("missing histogram entry for com.example.Foo, got %+v", idx.ClassHistogram)
+	}
+	if stats.Instances != 2 {
+		t.Errorf("Instances = %d, want 2", stats.Instances)
+	}
+	if stats.Bytes != 32 {
+		t.Errorf("Bytes = %d, want 32", stats.Bytes)
+	}
+}
+
+func TestBuildIndex_RetainedSize(t *testing.T) {
+	path := writeTestDumpFile(t, buildRefTestDump(t))
+
+	idx, err := BuildIndex(path)
+	if err != nil {
+		t.Fatalf("BuildIndex failed: %v", err)
+	}
+
+	// objB (0x200) is only reachable through objA's (0x100) single
+	// reference, so the two merge into one retained-size group covering
+	// both instances' bytes.
+	const objA, objB = 0x100, 0x200
+	if got := idx.RetainedSize(objA); got != 32 {
+		t.Errorf("RetainedSize(objA) = %d, want 32", got)
+	}
+	if got := idx.RetainedSize(objB); got != 32 {
+		t.Errorf("RetainedSize(objB) = %d, want 32", got)
+	}
+
+	// An object Index never saw has no retained size.
+	if got := idx.RetainedSize(0xdead); got != 0 {
+		t.Errorf("RetainedSize(unknown) = %d, want 0", got)
+	}
+}
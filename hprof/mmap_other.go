@@ -0,0 +1,18 @@
+//go:build !linux && !darwin
+// +build !linux,!darwin
+
+package hprof
+
+import "os"
+
+// mmapFile reads the whole file into memory. Windows support for the
+// equivalent of mmap (CreateFileMapping/MapViewOfFile) isn't wired up yet,
+// so BuildIndex falls back to a plain read here; it still works, just
+// without the reduced-RSS benefit mmapFile gives on Linux and macOS.
+func mmapFile(path string) (data []byte, closeFunc func() error, err error) {
+	data, err = os.ReadFile(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	return data, func() error { return nil }, nil
+}
@@ -0,0 +1,48 @@
+//go:build linux || darwin
+// +build linux darwin
+
+package hprof
+
+import (
+	"fmt"
+	"os"
+
+	"golang.org/x/sys/unix"
+)
+
+// mmapFile memory-maps path read-only and returns its contents along with a
+// function that unmaps and closes it. Mapping rather than reading the file
+// lets BuildIndex process dumps much larger than available RAM: pages are
+// faulted in by the kernel as Walk touches them, and can be evicted again
+// under memory pressure.
+func mmapFile(path string) (data []byte, closeFunc func() error, err error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, nil, err
+	}
+	if info.Size() == 0 {
+		f.Close()
+		return nil, func() error { return nil }, nil
+	}
+
+	data, err = unix.Mmap(int(f.Fd()), 0, int(info.Size()), unix.PROT_READ, unix.MAP_SHARED)
+	if err != nil {
+		f.Close()
+		return nil, nil, fmt.Errorf("failed to mmap %s: %w", path, err)
+	}
+
+	return data, func() error {
+		unmapErr := unix.Munmap(data)
+		closeErr := f.Close()
+		if unmapErr != nil {
+			return unmapErr
+		}
+		return closeErr
+	}, nil
+}
@@ -0,0 +1,90 @@
+package hprof
+
+// StringRecord associates an object ID with a UTF-8 string, referenced by
+// class names, field names, and stack frame details elsewhere in the file.
+type StringRecord struct {
+	ID   uint64
+	Text string
+}
+
+// LoadClass associates a class object ID with its fully-qualified name
+// (resolved through a prior StringRecord).
+type LoadClass struct {
+	ClassSerial      uint32
+	ClassObjectID    uint64
+	StackTraceSerial uint32
+	ClassNameID      uint64
+}
+
+// StackFrame describes one frame of a stack trace.
+type StackFrame struct {
+	ID           uint64
+	MethodNameID uint64
+	SignatureID  uint64
+	SourceFileID uint64
+	ClassSerial  uint32
+	LineNumber   int32
+}
+
+// StackTrace lists the frame IDs making up one captured stack trace, top
+// frame first.
+type StackTrace struct {
+	StackTraceSerial uint32
+	ThreadSerial     uint32
+	FrameIDs         []uint64
+}
+
+// RootJNIGlobal is a GC root held by a JNI global reference.
+type RootJNIGlobal struct {
+	ObjectID       uint64
+	JNIGlobalRefID uint64
+}
+
+// ClassDump describes a loaded class: its superclass, declared fields, and
+// instance size, which Index uses to compute histogram byte totals.
+type ClassDump struct {
+	ClassObjectID      uint64
+	StackTraceSerial   uint32
+	SuperClassObjectID uint64
+	InstanceSize       uint32
+	StaticFields       []StaticField
+	InstanceFields     []FieldDescriptor
+}
+
+// FieldDescriptor names a field declared by a class, without its value.
+type FieldDescriptor struct {
+	NameID uint64
+	Type   BasicType
+}
+
+// StaticField is a class's static field, with its constant value.
+type StaticField struct {
+	FieldDescriptor
+	Value uint64 // raw bytes of the value, widened to 64 bits
+}
+
+// InstanceDump is one object instance and its field values, packed
+// according to its class's InstanceFields (including inherited ones).
+type InstanceDump struct {
+	ObjectID         uint64
+	StackTraceSerial uint32
+	ClassObjectID    uint64
+	FieldValues      []byte // raw, undecoded field bytes
+}
+
+// ObjectArrayDump is an array of object references.
+type ObjectArrayDump struct {
+	ObjectID         uint64
+	StackTraceSerial uint32
+	ClassObjectID    uint64
+	Elements         []uint64
+}
+
+// PrimitiveArrayDump is an array of a primitive type.
+type PrimitiveArrayDump struct {
+	ObjectID         uint64
+	StackTraceSerial uint32
+	ElementType      BasicType
+	Length           uint32
+	RawElements      []byte // raw, undecoded element bytes
+}
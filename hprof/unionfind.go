@@ -0,0 +1,72 @@
+package hprof
+
+// unionFind is a disjoint-set forest over object IDs, tracking the total
+// byte size of each set as its objects are added and merged. It backs
+// Index's approximate retained-size computation.
+type unionFind struct {
+	parent map[uint64]uint64
+	rank   map[uint64]int
+	bytes  map[uint64]int64
+}
+
+func newUnionFind() *unionFind {
+	return &unionFind{
+		parent: make(map[uint64]uint64),
+		rank:   make(map[uint64]int),
+		bytes:  make(map[uint64]int64),
+	}
+}
+
+// add registers id as its own singleton set if it hasn't been seen before,
+// attributing size bytes to it. Calling add again for an already-known id
+// only adds size if it's non-zero (an object can be "touched" once with its
+// real size and again with 0 when merely referenced).
+func (uf *unionFind) add(id uint64, size int64) {
+	if _, ok := uf.parent[id]; !ok {
+		uf.parent[id] = id
+		uf.rank[id] = 0
+		uf.bytes[id] = size
+		return
+	}
+	if size != 0 {
+		root := uf.find(id)
+		uf.bytes[root] += size
+	}
+}
+
+func (uf *unionFind) find(id uint64) uint64 {
+	root, ok := uf.parent[id]
+	if !ok {
+		return id
+	}
+	for root != uf.parent[root] {
+		// Path halving.
+		uf.parent[root] = uf.parent[uf.parent[root]]
+		root = uf.parent[root]
+	}
+	uf.parent[id] = root
+	return root
+}
+
+func (uf *unionFind) union(a, b uint64) {
+	ra, rb := uf.find(a), uf.find(b)
+	if ra == rb {
+		return
+	}
+	if uf.rank[ra] < uf.rank[rb] {
+		ra, rb = rb, ra
+	}
+	uf.parent[rb] = ra
+	uf.bytes[ra] += uf.bytes[rb]
+	delete(uf.bytes, rb)
+	if uf.rank[ra] == uf.rank[rb] {
+		uf.rank[ra]++
+	}
+}
+
+func (uf *unionFind) bytesOf(id uint64) int64 {
+	if _, ok := uf.parent[id]; !ok {
+		return 0
+	}
+	return uf.bytes[uf.find(id)]
+}
@@ -0,0 +1,46 @@
+package hprof
+
+import "testing"
+
+func TestUnionFind_AddAndUnion(t *testing.T) {
+	uf := newUnionFind()
+	uf.add(1, 10)
+	uf.add(2, 20)
+
+	if got := uf.bytesOf(1); got != 10 {
+		t.Errorf("bytesOf(1) = %d, want 10", got)
+	}
+	if got := uf.bytesOf(2); got != 20 {
+		t.Errorf("bytesOf(2) = %d, want 20", got)
+	}
+
+	uf.union(1, 2)
+
+	root1, root2 := uf.find(1), uf.find(2)
+	if root1 != root2 {
+		t.Fatalf("find(1)=%d and find(2)=%d should be in the same set after union", root1, root2)
+	}
+	if got := uf.bytesOf(1); got != 30 {
+		t.Errorf("bytesOf(1) after union = %d, want 30", got)
+	}
+	if got := uf.bytesOf(2); got != 30 {
+		t.Errorf("bytesOf(2) after union = %d, want 30", got)
+	}
+}
+
+func TestUnionFind_AddTouchWithoutSize(t *testing.T) {
+	uf := newUnionFind()
+	uf.add(1, 0) // touched by a reference before its own size is known
+	uf.add(1, 5) // later touched with its real size
+
+	if got := uf.bytesOf(1); got != 5 {
+		t.Errorf("bytesOf(1) = %d, want 5", got)
+	}
+}
+
+func TestUnionFind_UnknownID(t *testing.T) {
+	uf := newUnionFind()
+	if got := uf.bytesOf(999); got != 0 {
+		t.Errorf("bytesOf(unknown) = %d, want 0", got)
+	}
+}
@@ -0,0 +1,33 @@
+package hprof
+
+// Visitor receives callbacks for each record Walk encounters, in file
+// order. Returning a non-nil error from any method aborts the walk and is
+// returned from Walk unchanged.
+//
+// Most callers only care about a handful of record kinds; embed NopVisitor
+// to get no-op defaults for the rest.
+type Visitor interface {
+	OnString(StringRecord) error
+	OnLoadClass(LoadClass) error
+	OnStackFrame(StackFrame) error
+	OnStackTrace(StackTrace) error
+	OnRootJNIGlobal(RootJNIGlobal) error
+	OnClassDump(ClassDump) error
+	OnInstanceDump(InstanceDump) error
+	OnObjectArrayDump(ObjectArrayDump) error
+	OnPrimitiveArrayDump(PrimitiveArrayDump) error
+}
+
+// NopVisitor implements Visitor with no-op methods. Embed it in a visitor
+// type to only override the callbacks you need.
+type NopVisitor struct{}
+
+func (NopVisitor) OnString(StringRecord) error                   { return nil }
+func (NopVisitor) OnLoadClass(LoadClass) error                   { return nil }
+func (NopVisitor) OnStackFrame(StackFrame) error                 { return nil }
+func (NopVisitor) OnStackTrace(StackTrace) error                 { return nil }
+func (NopVisitor) OnRootJNIGlobal(RootJNIGlobal) error           { return nil }
+func (NopVisitor) OnClassDump(ClassDump) error                   { return nil }
+func (NopVisitor) OnInstanceDump(InstanceDump) error             { return nil }
+func (NopVisitor) OnObjectArrayDump(ObjectArrayDump) error       { return nil }
+func (NopVisitor) OnPrimitiveArrayDump(PrimitiveArrayDump) error { return nil }
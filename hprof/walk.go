@@ -0,0 +1,492 @@
+package hprof
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+)
+
+// Walk streams every record in an HPROF file read through r, starting from
+// its header, and invokes the matching Visitor method for each one. Unlike
+// File.Walk, this accepts any io.ReaderAt (for example a memory-mapped
+// region), so callers aren't required to go through Open.
+func Walk(r io.ReaderAt, v Visitor) error {
+	hdr, err := readHeader(io.NewSectionReader(r, 0, math.MaxInt64))
+	if err != nil {
+		return err
+	}
+
+	body := io.NewSectionReader(r, int64(headerSize(hdr)), math.MaxInt64)
+	return walk(bufio.NewReaderSize(body, 1<<20), int(hdr.IdentifierSize), v)
+}
+
+// peekHeader reads just the header of an HPROF file without walking its
+// records, so callers can learn the identifier size up front.
+func peekHeader(r io.ReaderAt) (Header, error) {
+	return readHeader(io.NewSectionReader(r, 0, math.MaxInt64))
+}
+
+// idReader reads object/class IDs, whose width is fixed per-file by the
+// header's IdentifierSize (4 bytes on 32-bit JVMs, 8 bytes on 64-bit ones).
+type idReader struct {
+	br     *bufio.Reader
+	idSize int
+}
+
+func (r idReader) readID() (uint64, error) {
+	buf := make([]byte, r.idSize)
+	if _, err := io.ReadFull(r.br, buf); err != nil {
+		return 0, err
+	}
+	var id uint64
+	for _, b := range buf {
+		id = id<<8 | uint64(b)
+	}
+	return id, nil
+}
+
+func (r idReader) readU32() (uint32, error) {
+	var v uint32
+	err := binary.Read(r.br, binary.BigEndian, &v)
+	return v, err
+}
+
+func (r idReader) readU8() (byte, error) {
+	return r.br.ReadByte()
+}
+
+func (r idReader) skip(n int) error {
+	_, err := io.CopyN(io.Discard, r.br, int64(n))
+	return err
+}
+
+// walk dispatches each top-level record to its parser until EOF.
+func walk(br *bufio.Reader, idSize int, v Visitor) error {
+	ir := idReader{br: br, idSize: idSize}
+
+	for {
+		tagByte, err := ir.readU8()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read record tag: %w", err)
+		}
+
+		// tag, microseconds-since-dump-start (ignored), and body length.
+		if _, err := ir.readU32(); err != nil {
+			return fmt.Errorf("failed to read record timestamp: %w", err)
+		}
+		length, err := ir.readU32()
+		if err != nil {
+			return fmt.Errorf("failed to read record length: %w", err)
+		}
+
+		body := io.LimitReader(br, int64(length))
+		bodyReader := idReader{br: bufio.NewReader(body), idSize: idSize}
+
+		if err := dispatch(Tag(tagByte), bodyReader, v); err != nil {
+			return err
+		}
+
+		// Discard whatever the specific parser didn't consume, so a parser
+		// that only understands part of a record doesn't desync the stream.
+		if _, err := io.Copy(io.Discard, body); err != nil {
+			return fmt.Errorf("failed to skip record body: %w", err)
+		}
+	}
+}
+
+func dispatch(tag Tag, r idReader, v Visitor) error {
+	switch tag {
+	case TagString:
+		return parseString(r, v)
+	case TagLoadClass:
+		return parseLoadClass(r, v)
+	case TagStackFrame:
+		return parseStackFrame(r, v)
+	case TagStackTrace:
+		return parseStackTrace(r, v)
+	case TagHeapDump, TagHeapDumpSegment:
+		return parseHeapDump(r, v)
+	default:
+		// Unrecognized or uninteresting top-level record (e.g.
+		// HEAP_SUMMARY, CPU_SAMPLES): the caller in walk() already skips
+		// whatever bytes remain in its body.
+		return nil
+	}
+}
+
+func parseString(r idReader, v Visitor) error {
+	id, err := r.readID()
+	if err != nil {
+		return err
+	}
+	text, err := io.ReadAll(r.br)
+	if err != nil {
+		return err
+	}
+	return v.OnString(StringRecord{ID: id, Text: string(text)})
+}
+
+func parseLoadClass(r idReader, v Visitor) error {
+	classSerial, err := r.readU32()
+	if err != nil {
+		return err
+	}
+	classObjectID, err := r.readID()
+	if err != nil {
+		return err
+	}
+	stackTraceSerial, err := r.readU32()
+	if err != nil {
+		return err
+	}
+	classNameID, err := r.readID()
+	if err != nil {
+		return err
+	}
+	return v.OnLoadClass(LoadClass{
+		ClassSerial:      classSerial,
+		ClassObjectID:    classObjectID,
+		StackTraceSerial: stackTraceSerial,
+		ClassNameID:      classNameID,
+	})
+}
+
+func parseStackFrame(r idReader, v Visitor) error {
+	id, err := r.readID()
+	if err != nil {
+		return err
+	}
+	methodNameID, err := r.readID()
+	if err != nil {
+		return err
+	}
+	signatureID, err := r.readID()
+	if err != nil {
+		return err
+	}
+	sourceFileID, err := r.readID()
+	if err != nil {
+		return err
+	}
+	classSerial, err := r.readU32()
+	if err != nil {
+		return err
+	}
+	var lineNumber int32
+	if err := binary.Read(r.br, binary.BigEndian, &lineNumber); err != nil {
+		return err
+	}
+	return v.OnStackFrame(StackFrame{
+		ID:           id,
+		MethodNameID: methodNameID,
+		SignatureID:  signatureID,
+		SourceFileID: sourceFileID,
+		ClassSerial:  classSerial,
+		LineNumber:   lineNumber,
+	})
+}
+
+func parseStackTrace(r idReader, v Visitor) error {
+	stackTraceSerial, err := r.readU32()
+	if err != nil {
+		return err
+	}
+	threadSerial, err := r.readU32()
+	if err != nil {
+		return err
+	}
+	numFrames, err := r.readU32()
+	if err != nil {
+		return err
+	}
+	frameIDs := make([]uint64, 0, numFrames)
+	for i := uint32(0); i < numFrames; i++ {
+		id, err := r.readID()
+		if err != nil {
+			return err
+		}
+		frameIDs = append(frameIDs, id)
+	}
+	return v.OnStackTrace(StackTrace{
+		StackTraceSerial: stackTraceSerial,
+		ThreadSerial:     threadSerial,
+		FrameIDs:         frameIDs,
+	})
+}
+
+// parseHeapDump walks the sub-records of a HEAP_DUMP/HEAP_DUMP_SEGMENT
+// record until its body is exhausted.
+func parseHeapDump(r idReader, v Visitor) error {
+	for {
+		subTag, err := r.readU8()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		switch SubTag(subTag) {
+		case SubTagRootUnknown, SubTagRootStickyClass, SubTagRootMonitorUsed, SubTagRootThreadObj:
+			if _, err := r.readID(); err != nil {
+				return err
+			}
+		case SubTagRootJNIGlobal:
+			objectID, err := r.readID()
+			if err != nil {
+				return err
+			}
+			refID, err := r.readID()
+			if err != nil {
+				return err
+			}
+			if err := v.OnRootJNIGlobal(RootJNIGlobal{ObjectID: objectID, JNIGlobalRefID: refID}); err != nil {
+				return err
+			}
+		case SubTagRootJNILocal, SubTagRootJavaFrame:
+			if _, err := r.readID(); err != nil {
+				return err
+			}
+			if err := r.skip(8); err != nil { // thread serial number + frame number
+				return err
+			}
+		case SubTagRootNativeStack, SubTagRootThreadBlock:
+			if _, err := r.readID(); err != nil {
+				return err
+			}
+			if err := r.skip(4); err != nil { // thread serial number
+				return err
+			}
+		case SubTagClassDump:
+			if err := parseClassDump(r, v); err != nil {
+				return err
+			}
+		case SubTagInstanceDump:
+			if err := parseInstanceDump(r, v); err != nil {
+				return err
+			}
+		case SubTagObjArrayDump:
+			if err := parseObjectArrayDump(r, v); err != nil {
+				return err
+			}
+		case SubTagPrimArrayDump:
+			if err := parsePrimitiveArrayDump(r, v); err != nil {
+				return err
+			}
+		default:
+			return fmt.Errorf("unrecognized heap dump sub-record tag 0x%02x", subTag)
+		}
+	}
+}
+
+func typeSize(t BasicType, idSize int) int {
+	switch t {
+	case TypeObject:
+		return idSize
+	case TypeBoolean, TypeByte:
+		return 1
+	case TypeChar, TypeShort:
+		return 2
+	case TypeFloat, TypeInt:
+		return 4
+	case TypeDouble, TypeLong:
+		return 8
+	default:
+		return 0
+	}
+}
+
+func parseClassDump(r idReader, v Visitor) error {
+	classObjectID, err := r.readID()
+	if err != nil {
+		return err
+	}
+	stackTraceSerial, err := r.readU32()
+	if err != nil {
+		return err
+	}
+	superClassObjectID, err := r.readID()
+	if err != nil {
+		return err
+	}
+	// classloader ID, signers ID, protection domain ID, and two reserved IDs.
+	for i := 0; i < 5; i++ {
+		if _, err := r.readID(); err != nil {
+			return err
+		}
+	}
+	instanceSize, err := r.readU32()
+	if err != nil {
+		return err
+	}
+
+	var constPoolSize uint16
+	if err := binary.Read(r.br, binary.BigEndian, &constPoolSize); err != nil {
+		return err
+	}
+	for i := uint16(0); i < constPoolSize; i++ {
+		if err := r.skip(2); err != nil { // constant pool index
+			return err
+		}
+		typ, err := r.readU8()
+		if err != nil {
+			return err
+		}
+		if err := r.skip(typeSize(BasicType(typ), r.idSize)); err != nil {
+			return err
+		}
+	}
+
+	var numStatics uint16
+	if err := binary.Read(r.br, binary.BigEndian, &numStatics); err != nil {
+		return err
+	}
+	statics := make([]StaticField, 0, numStatics)
+	for i := uint16(0); i < numStatics; i++ {
+		nameID, err := r.readID()
+		if err != nil {
+			return err
+		}
+		typ, err := r.readU8()
+		if err != nil {
+			return err
+		}
+		size := typeSize(BasicType(typ), r.idSize)
+		buf := make([]byte, size)
+		if _, err := io.ReadFull(r.br, buf); err != nil {
+			return err
+		}
+		var value uint64
+		for _, b := range buf {
+			value = value<<8 | uint64(b)
+		}
+		statics = append(statics, StaticField{
+			FieldDescriptor: FieldDescriptor{NameID: nameID, Type: BasicType(typ)},
+			Value:           value,
+		})
+	}
+
+	var numFields uint16
+	if err := binary.Read(r.br, binary.BigEndian, &numFields); err != nil {
+		return err
+	}
+	fields := make([]FieldDescriptor, 0, numFields)
+	for i := uint16(0); i < numFields; i++ {
+		nameID, err := r.readID()
+		if err != nil {
+			return err
+		}
+		typ, err := r.readU8()
+		if err != nil {
+			return err
+		}
+		fields = append(fields, FieldDescriptor{NameID: nameID, Type: BasicType(typ)})
+	}
+
+	return v.OnClassDump(ClassDump{
+		ClassObjectID:      classObjectID,
+		StackTraceSerial:   stackTraceSerial,
+		SuperClassObjectID: superClassObjectID,
+		InstanceSize:       instanceSize,
+		StaticFields:       statics,
+		InstanceFields:     fields,
+	})
+}
+
+func parseInstanceDump(r idReader, v Visitor) error {
+	objectID, err := r.readID()
+	if err != nil {
+		return err
+	}
+	stackTraceSerial, err := r.readU32()
+	if err != nil {
+		return err
+	}
+	classObjectID, err := r.readID()
+	if err != nil {
+		return err
+	}
+	numBytes, err := r.readU32()
+	if err != nil {
+		return err
+	}
+	buf := make([]byte, numBytes)
+	if _, err := io.ReadFull(r.br, buf); err != nil {
+		return err
+	}
+	return v.OnInstanceDump(InstanceDump{
+		ObjectID:         objectID,
+		StackTraceSerial: stackTraceSerial,
+		ClassObjectID:    classObjectID,
+		FieldValues:      buf,
+	})
+}
+
+func parseObjectArrayDump(r idReader, v Visitor) error {
+	objectID, err := r.readID()
+	if err != nil {
+		return err
+	}
+	stackTraceSerial, err := r.readU32()
+	if err != nil {
+		return err
+	}
+	length, err := r.readU32()
+	if err != nil {
+		return err
+	}
+	classObjectID, err := r.readID()
+	if err != nil {
+		return err
+	}
+	elements := make([]uint64, 0, length)
+	for i := uint32(0); i < length; i++ {
+		id, err := r.readID()
+		if err != nil {
+			return err
+		}
+		elements = append(elements, id)
+	}
+	return v.OnObjectArrayDump(ObjectArrayDump{
+		ObjectID:         objectID,
+		StackTraceSerial: stackTraceSerial,
+		ClassObjectID:    classObjectID,
+		Elements:         elements,
+	})
+}
+
+func parsePrimitiveArrayDump(r idReader, v Visitor) error {
+	objectID, err := r.readID()
+	if err != nil {
+		return err
+	}
+	stackTraceSerial, err := r.readU32()
+	if err != nil {
+		return err
+	}
+	length, err := r.readU32()
+	if err != nil {
+		return err
+	}
+	elemType, err := r.readU8()
+	if err != nil {
+		return err
+	}
+	size := typeSize(BasicType(elemType), r.idSize)
+	buf := make([]byte, int(length)*size)
+	if _, err := io.ReadFull(r.br, buf); err != nil {
+		return err
+	}
+	return v.OnPrimitiveArrayDump(PrimitiveArrayDump{
+		ObjectID:         objectID,
+		StackTraceSerial: stackTraceSerial,
+		ElementType:      BasicType(elemType),
+		Length:           length,
+		RawElements:      buf,
+	})
+}
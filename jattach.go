@@ -1,14 +1,16 @@
-//go:build (linux || darwin || windows) && (amd64 || arm64 || 386)
-// +build linux darwin windows
-// +build amd64 arm64 386
+//go:build (windows && (amd64 || arm64 || 386)) || ((linux || darwin) && (amd64 || arm64))
 
 // Package jattach provides Go bindings for the jattach utility, which allows
 // sending commands to running JVM processes via the Dynamic Attach mechanism.
 //
-// This package wraps the native C implementation of jattach using CGo, providing
-// a type-safe, idiomatic Go API for interacting with Java Virtual Machines.
+// The HotSpot Dynamic Attach protocol is implemented natively in Go (see the
+// src/posix and src/windows subpackages), so this package has no CGo
+// dependency and cross-compiles like any other pure Go code, within the
+// platforms below.
 //
-// Supported platforms: Linux, macOS, and Windows on amd64, arm64, and 386 architectures.
+// Supported platforms: Linux and macOS on amd64 and arm64, and Windows on
+// amd64, arm64, and 386. There is no src/posix 386 implementation, so
+// GOARCH=386 only cross-compiles on Windows.
 //
 // Example usage:
 //
@@ -31,6 +33,7 @@ package jattach
 
 import (
 	"bytes"
+	"context"
 	"fmt"
 	"io"
 	"os"
@@ -76,49 +79,87 @@ const (
 // The command output is printed to stdout.
 // Returns the exit code from the JVM command.
 func Attach(pid int, cmd Command, args ...string) (int, error) {
-	cmdArgs := append([]string{string(cmd)}, args...)
-	return callJattach(pid, cmdArgs, true)
+	return AttachContext(context.Background(), pid, os.Stdout, cmd, args...)
 }
 
 // AttachWithOutput sends a command to a JVM process and captures the output.
 // Unlike Attach, this function captures stdout instead of printing it.
 // Returns the captured output, exit code, and any error.
 func AttachWithOutput(pid int, cmd Command, args ...string) (string, int, error) {
-	// Create a pipe to capture stdout
-	r, w, err := os.Pipe()
-	if err != nil {
-		return "", 1, fmt.Errorf("failed to create pipe: %w", err)
-	}
+	var buf bytes.Buffer
+	exitCode, err := AttachContext(context.Background(), pid, &buf, cmd, args...)
+	return buf.String(), exitCode, err
+}
 
-	// Save original stdout and restore it when done
-	oldStdout := os.Stdout
-	defer func() {
-		os.Stdout = oldStdout
-	}()
+// AttachContext sends a command to a JVM process, streaming its response
+// directly to out as it arrives rather than buffering the whole payload in
+// memory. This makes it safe to use with commands that can produce a lot of
+// output, such as ThreadDump or InspectHeap on a JVM with many threads or
+// classes.
+//
+// ctx governs the whole attach handshake: if it is cancelled or its
+// deadline passes before the JVM responds, the underlying connection is
+// closed and AttachContext returns ctx.Err().
+//
+// AttachContext writes only to out and never touches the process-global
+// os.Stdout, so it is safe to call concurrently from multiple goroutines.
+// Returns the exit code from the JVM command.
+func AttachContext(ctx context.Context, pid int, out io.Writer, cmd Command, args ...string) (int, error) {
+	cmdArgs := append([]string{string(cmd)}, args...)
+	return callJattach(ctx, pid, cmdArgs, out)
+}
 
-	// Redirect stdout to our pipe
-	os.Stdout = w
+// AttachReader sends a command to a JVM process and returns an
+// io.ReadCloser over its response, so the caller can pull output at its own
+// pace instead of it being pushed into a writer. The returned exit code is
+// set once a Read has returned io.EOF or the attach error; closing the
+// reader early does not wait for it to be set.
+//
+// ctx governs the whole attach handshake; once it is done the connection is
+// closed and any blocked Read returns ctx.Err().
+func AttachReader(ctx context.Context, pid int, cmd Command, args ...string) (io.ReadCloser, *int, error) {
+	pr, pw := io.Pipe()
+	exitCode := new(int)
 
-	// Capture output in a goroutine
-	outputChan := make(chan string, 1)
 	go func() {
-		var buf bytes.Buffer
-		io.Copy(&buf, r)
-		outputChan <- buf.String()
+		code, err := AttachContext(ctx, pid, pw, cmd, args...)
+		*exitCode = code
+		pw.CloseWithError(err)
 	}()
 
-	// Execute the command
-	cmdArgs := append([]string{string(cmd)}, args...)
-	exitCode, err := callJattach(pid, cmdArgs, true)
-
-	// Close the write end of the pipe
-	w.Close()
+	return pr, exitCode, nil
+}
 
-	// Read the captured output
-	output := <-outputChan
-	r.Close()
+// AttachOptions configures how AttachInNamespace crosses into a target
+// process's containers.
+type AttachOptions struct {
+	// EnterMountNamespace, if true, joins the target process's mount
+	// namespace (via setns(CLONE_NEWNS)) for the rest of the attach
+	// (trigger-file creation, socket lookup and connection). Most
+	// containerized JVMs can be reached without
+	// this, since /proc/<pid>/root and /proc/<pid>/cwd already resolve
+	// into the target's mount namespace from the host; set it when those
+	// paths aren't usable directly (e.g. a restrictive container runtime).
+	//
+	// Only supported on Linux; ignored elsewhere.
+	EnterMountNamespace bool
+}
 
-	return output, exitCode, err
+// AttachInNamespace sends a command to a JVM process that may be running in
+// a different PID and mount namespace than the caller, such as a JVM inside
+// a Docker, containerd, or Podman container. hostPid is the process's PID
+// as seen from the caller's (host) namespace.
+//
+// AttachInNamespace resolves the PID HotSpot actually writes into its
+// attach filenames from /proc/<hostPid>/status' NSpid field, matches the
+// target's effective UID/GID so the trigger file passes HotSpot's ownership
+// check, and otherwise behaves like AttachContext.
+//
+// Only supported on Linux; on other platforms it returns an error, since
+// PID namespaces are a Linux-specific concept.
+func AttachInNamespace(ctx context.Context, hostPid int, out io.Writer, opts AttachOptions, cmd Command, args ...string) (int, error) {
+	cmdArgs := append([]string{string(cmd)}, args...)
+	return attachInNamespace(ctx, hostPid, cmdArgs, out, opts)
 }
 
 // GetThreadDump retrieves a thread dump from the target JVM.
@@ -0,0 +1,79 @@
+//go:build (windows && (amd64 || arm64 || 386)) || ((linux || darwin) && (amd64 || arm64))
+
+package jattach
+
+import (
+	"bytes"
+	"context"
+	"runtime"
+	"sync"
+	"time"
+)
+
+// Result is the outcome of one attach performed by AttachAll.
+type Result struct {
+	Output   string
+	ExitCode int
+	Err      error
+}
+
+// AttachAll sends cmd to every pid in pids concurrently, using a worker
+// pool bounded by runtime.GOMAXPROCS(0) so a large fleet scan doesn't open
+// thousands of sockets at once. Each attach gets its own perPIDTimeout; a
+// pid that doesn't respond in time fails with ctx.Err() without blocking
+// the others.
+//
+// If ctx is cancelled before a given pid's attach starts, that pid's
+// Result.Err is ctx.Err() and no attach is attempted.
+func AttachAll(ctx context.Context, pids []int, perPIDTimeout time.Duration, cmd Command, args ...string) map[int]Result {
+	results := make(map[int]Result, len(pids))
+	var mu sync.Mutex
+
+	workers := runtime.GOMAXPROCS(0)
+	if workers > len(pids) {
+		workers = len(pids)
+	}
+	if workers < 1 {
+		workers = 1
+	}
+
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	wg.Add(workers)
+	for i := 0; i < workers; i++ {
+		go func() {
+			defer wg.Done()
+			for pid := range jobs {
+				res := attachOne(ctx, pid, perPIDTimeout, cmd, args...)
+				mu.Lock()
+				results[pid] = res
+				mu.Unlock()
+			}
+		}()
+	}
+
+	for _, pid := range pids {
+		jobs <- pid
+	}
+	close(jobs)
+	wg.Wait()
+
+	return results
+}
+
+func attachOne(ctx context.Context, pid int, perPIDTimeout time.Duration, cmd Command, args ...string) Result {
+	if err := ctx.Err(); err != nil {
+		return Result{Err: err}
+	}
+
+	attachCtx := ctx
+	if perPIDTimeout > 0 {
+		var cancel context.CancelFunc
+		attachCtx, cancel = context.WithTimeout(ctx, perPIDTimeout)
+		defer cancel()
+	}
+
+	var buf bytes.Buffer
+	exitCode, err := AttachContext(attachCtx, pid, &buf, cmd, args...)
+	return Result{Output: buf.String(), ExitCode: exitCode, Err: err}
+}
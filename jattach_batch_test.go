@@ -0,0 +1,61 @@
+//go:build (windows && (amd64 || arm64 || 386)) || ((linux || darwin) && (amd64 || arm64))
+
+package jattach
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestAttachAll_InvalidPIDs(t *testing.T) {
+	pids := []int{0, -1, -2}
+	results := AttachAll(context.Background(), pids, time.Second, Properties)
+
+	if len(results) != len(pids) {
+		t.Fatalf("expected %d results, got %d", len(pids), len(results))
+	}
+	for _, pid := range pids {
+		res, ok := results[pid]
+		if !ok {
+			t.Errorf("missing result for pid %d", pid)
+			continue
+		}
+		if res.Err == nil {
+			t.Errorf("expected error for invalid pid %d, got nil", pid)
+		}
+	}
+}
+
+func TestAttachAll_CancelledContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	pids := []int{1, 2, 3}
+	results := AttachAll(ctx, pids, time.Second, Properties)
+
+	for _, pid := range pids {
+		res, ok := results[pid]
+		if !ok {
+			t.Fatalf("missing result for pid %d", pid)
+		}
+		if res.Err != context.Canceled {
+			t.Errorf("pid %d: expected context.Canceled, got %v", pid, res.Err)
+		}
+	}
+}
+
+// TestAttachAll_NoSharedState exercises AttachAll with more pids than
+// GOMAXPROCS so several workers write to results concurrently; run with
+// -race to catch a mutex that doesn't actually guard the map.
+func TestAttachAll_NoSharedState(t *testing.T) {
+	pids := make([]int, 0, 50)
+	for i := 1; i <= 50; i++ {
+		pids = append(pids, -i)
+	}
+
+	results := AttachAll(context.Background(), pids, time.Second, Properties)
+	if len(results) != len(pids) {
+		t.Fatalf("expected %d results, got %d", len(pids), len(results))
+	}
+}
@@ -0,0 +1,20 @@
+//go:build linux && (amd64 || arm64)
+// +build linux
+// +build amd64 arm64
+
+package jattach
+
+import (
+	"context"
+	"io"
+
+	"github.com/vlsi/jattach/v2/src/posix"
+)
+
+// attachInNamespace delegates to the Linux-specific namespace-aware
+// implementation.
+func attachInNamespace(ctx context.Context, hostPid int, args []string, out io.Writer, opts AttachOptions) (int, error) {
+	return posix.CallJattachInNamespace(ctx, hostPid, args, out, posix.NamespaceOptions{
+		EnterMountNamespace: opts.EnterMountNamespace,
+	})
+}
@@ -0,0 +1,19 @@
+//go:build (darwin || windows) && (amd64 || arm64 || 386)
+// +build darwin windows
+// +build amd64 arm64 386
+
+package jattach
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"runtime"
+)
+
+// attachInNamespace is unsupported outside Linux: PID namespaces are a
+// Linux-specific concept, and macOS/Windows JVMs are always attached from
+// the same namespace they run in.
+func attachInNamespace(_ context.Context, _ int, _ []string, _ io.Writer, _ AttachOptions) (int, error) {
+	return 1, fmt.Errorf("AttachInNamespace is not supported on %s", runtime.GOOS)
+}
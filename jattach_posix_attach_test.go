@@ -0,0 +1,122 @@
+//go:build (linux || darwin) && (amd64 || arm64)
+// +build linux darwin
+// +build amd64 arm64
+
+package jattach
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+// attachSocketPath mirrors src/posix's tmpDir/socketPath so the test can set
+// up a stub JVM at the exact path CallJattach will look for.
+func attachSocketPath(pid int) string {
+	dir := "/tmp"
+	if runtime.GOOS == "darwin" {
+		if d := os.Getenv("TMPDIR"); d != "" {
+			dir = strings.TrimRight(d, "/")
+		}
+	}
+	return filepath.Join(dir, fmt.Sprintf(".java_pid%d", pid))
+}
+
+// listenAsStubJVM pre-creates the attach socket for the current process so
+// CallJattach finds it already present and skips the SIGQUIT trigger path
+// entirely (sending a real SIGQUIT to the test binary would be disastrous).
+func listenAsStubJVM(t *testing.T) net.Listener {
+	t.Helper()
+	sockPath := attachSocketPath(os.Getpid())
+	os.Remove(sockPath)
+
+	ln, err := net.Listen("unix", sockPath)
+	if err != nil {
+		t.Fatalf("failed to listen on %s: %v", sockPath, err)
+	}
+	t.Cleanup(func() {
+		ln.Close()
+		os.Remove(sockPath)
+	})
+	return ln
+}
+
+func TestAttachContext_ContextCancellation(t *testing.T) {
+	ln := listenAsStubJVM(t)
+
+	accepted := make(chan net.Conn, 1)
+	go func() {
+		if conn, err := ln.Accept(); err == nil {
+			accepted <- conn
+		}
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, err := AttachContext(ctx, os.Getpid(), io.Discard, Properties)
+	elapsed := time.Since(start)
+
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("AttachContext returned %v, want context.DeadlineExceeded", err)
+	}
+	if elapsed > 2*time.Second {
+		t.Errorf("AttachContext took %s, expected ctx to unblock it promptly", elapsed)
+	}
+
+	select {
+	case conn := <-accepted:
+		conn.Close()
+	case <-time.After(time.Second):
+		t.Error("stub JVM never accepted the attach connection")
+	}
+}
+
+func TestAttachReader_ContextCancellation(t *testing.T) {
+	ln := listenAsStubJVM(t)
+
+	accepted := make(chan net.Conn, 1)
+	go func() {
+		if conn, err := ln.Accept(); err == nil {
+			accepted <- conn
+		}
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	r, exitCode, err := AttachReader(ctx, os.Getpid(), Properties)
+	if err != nil {
+		t.Fatalf("AttachReader returned an error up front: %v", err)
+	}
+
+	_, readErr := io.Copy(io.Discard, r)
+	elapsed := time.Since(start)
+
+	if !errors.Is(readErr, context.DeadlineExceeded) {
+		t.Errorf("reading AttachReader's output returned %v, want context.DeadlineExceeded", readErr)
+	}
+	if elapsed > 2*time.Second {
+		t.Errorf("AttachReader took %s, expected ctx to unblock it promptly", elapsed)
+	}
+	if *exitCode != 1 {
+		t.Errorf("exit code = %d, want 1 (the error path's default)", *exitCode)
+	}
+
+	select {
+	case conn := <-accepted:
+		conn.Close()
+	case <-time.After(time.Second):
+		t.Error("stub JVM never accepted the attach connection")
+	}
+}
@@ -4,9 +4,14 @@
 
 package jattach
 
-import "github.com/vlsi/jattach/v2/src/posix"
+import (
+	"context"
+	"io"
+
+	"github.com/vlsi/jattach/v2/src/posix"
+)
 
 // callJattach delegates to the POSIX-specific implementation
-func callJattach(pid int, args []string, printOutput bool) (int, error) {
-	return posix.CallJattach(pid, args, printOutput)
+func callJattach(ctx context.Context, pid int, args []string, out io.Writer) (int, error) {
+	return posix.CallJattach(ctx, pid, args, out)
 }
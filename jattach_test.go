@@ -1,10 +1,10 @@
-//go:build (linux || darwin || windows) && (amd64 || arm64 || 386)
-// +build linux darwin windows
-// +build amd64 arm64 386
+//go:build (windows && (amd64 || arm64 || 386)) || ((linux || darwin) && (amd64 || arm64))
 
 package jattach
 
 import (
+	"context"
+	"io"
 	"testing"
 )
 
@@ -36,12 +36,12 @@ func TestCommandConstants(t *testing.T) {
 
 func TestAttach_InvalidPID(t *testing.T) {
 	// Test with invalid PID
-	_, err := callJattach(0, []string{"properties"}, true)
+	_, err := callJattach(context.Background(), 0, []string{"properties"}, io.Discard)
 	if err == nil {
 		t.Error("Expected error for invalid PID, got nil")
 	}
 
-	_, err = callJattach(-1, []string{"properties"}, true)
+	_, err = callJattach(context.Background(), -1, []string{"properties"}, io.Discard)
 	if err == nil {
 		t.Error("Expected error for negative PID, got nil")
 	}
@@ -49,7 +49,7 @@ func TestAttach_InvalidPID(t *testing.T) {
 
 func TestAttach_NoCommand(t *testing.T) {
 	// Test with no command
-	_, err := callJattach(1, []string{}, true)
+	_, err := callJattach(context.Background(), 1, []string{}, io.Discard)
 	if err == nil {
 		t.Error("Expected error for empty command, got nil")
 	}
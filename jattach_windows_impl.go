@@ -4,9 +4,14 @@
 
 package jattach
 
-import "github.com/vlsi/jattach/v2/src/windows"
+import (
+	"context"
+	"io"
+
+	"github.com/vlsi/jattach/v2/src/windows"
+)
 
 // callJattach delegates to the Windows-specific implementation
-func callJattach(pid int, args []string, printOutput bool) (int, error) {
-	return windows.CallJattach(pid, args, printOutput)
+func callJattach(ctx context.Context, pid int, args []string, out io.Writer) (int, error) {
+	return windows.CallJattach(ctx, pid, args, out)
 }
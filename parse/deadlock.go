@@ -0,0 +1,99 @@
+package parse
+
+import "strings"
+
+// Deadlock describes a cycle of threads each waiting on a monitor held by
+// the next.
+type Deadlock struct {
+	// Threads lists the thread names in the cycle, in wait order: Threads[i]
+	// is blocked on a monitor held by Threads[i+1], and the last thread is
+	// blocked on a monitor held by Threads[0].
+	Threads []string
+}
+
+// FindDeadlocks walks each thread's "waiting to lock <addr>" frame against
+// the "locked <addr>" monitors held by other threads and reports any cycles
+// it finds. A thread dump with no deadlocks returns a nil slice.
+func (d ThreadDump) FindDeadlocks() []Deadlock {
+	holderOf := make(map[string]string) // monitor address -> holding thread name
+	for _, t := range d.Threads {
+		for _, frame := range t.StackFrames {
+			for _, lock := range frame.Locked {
+				holderOf[lock.Address] = t.Name
+			}
+		}
+	}
+
+	waitsFor := make(map[string]string) // thread name -> thread name it's blocked on
+	for _, t := range d.Threads {
+		if t.waitingOn == "" {
+			continue
+		}
+		if holder, ok := holderOf[t.waitingOn]; ok && holder != t.Name {
+			waitsFor[t.Name] = holder
+		}
+	}
+
+	var deadlocks []Deadlock
+	reported := make(map[string]bool)
+	for start := range waitsFor {
+		cycle := findCycle(start, waitsFor)
+		if cycle == nil {
+			continue
+		}
+		key := strings.Join(cycle, "\x00")
+		if reported[key] {
+			continue
+		}
+		reported[key] = true
+		deadlocks = append(deadlocks, Deadlock{Threads: cycle})
+	}
+	return deadlocks
+}
+
+// findCycle follows waitsFor starting at start and returns the cycle it
+// leads back into, normalized to start at its lexicographically smallest
+// member so the same cycle reached from different starting threads
+// produces an identical result. Returns nil if start's wait chain doesn't
+// loop back on itself.
+func findCycle(start string, waitsFor map[string]string) []string {
+	var chain []string
+	visited := make(map[string]int)
+	cur := start
+	for {
+		if idx, ok := visited[cur]; ok {
+			return normalizeCycle(chain[idx:])
+		}
+		visited[cur] = len(chain)
+		chain = append(chain, cur)
+
+		next, ok := waitsFor[cur]
+		if !ok {
+			return nil
+		}
+		cur = next
+	}
+}
+
+// normalizeCycle rotates cycle so it starts at its smallest element.
+func normalizeCycle(cycle []string) []string {
+	minIdx := 0
+	for i, name := range cycle {
+		if name < cycle[minIdx] {
+			minIdx = i
+		}
+	}
+	rotated := make([]string, len(cycle))
+	for i := range cycle {
+		rotated[i] = cycle[(minIdx+i)%len(cycle)]
+	}
+	return rotated
+}
+
+// String renders the cycle as "A -> B -> C -> A" for logging.
+func (dl Deadlock) String() string {
+	if len(dl.Threads) == 0 {
+		return ""
+	}
+	return strings.Join(append(append([]string{}, dl.Threads...), dl.Threads[0]), " -> ")
+}
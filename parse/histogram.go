@@ -0,0 +1,80 @@
+package parse
+
+import (
+	"bufio"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Histogram is the parsed form of an "inspectheap" (jmap -histo-equivalent)
+// response.
+type Histogram struct {
+	Rows  []HistogramRow
+	Total HistogramRow // the synthetic "Total" row HotSpot appends, if present
+}
+
+// HistogramRow is a single class entry in a heap histogram.
+type HistogramRow struct {
+	Rank      int
+	Instances int64
+	Bytes     int64
+	ClassName string
+}
+
+// ParseHistogram parses the output of an InspectHeap command into a
+// Histogram. Lines that don't look like a histogram row (the header and the
+// "----" separator) are skipped rather than treated as errors.
+func ParseHistogram(raw string) (Histogram, error) {
+	var h Histogram
+
+	scanner := bufio.NewScanner(strings.NewReader(raw))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 3 {
+			continue
+		}
+
+		if strings.EqualFold(fields[0], "Total") {
+			instances, err := strconv.ParseInt(fields[1], 10, 64)
+			if err != nil {
+				continue
+			}
+			bytes, err := strconv.ParseInt(fields[2], 10, 64)
+			if err != nil {
+				continue
+			}
+			h.Total = HistogramRow{Instances: instances, Bytes: bytes}
+			continue
+		}
+
+		rank, err := strconv.Atoi(strings.TrimSuffix(fields[0], ":"))
+		if err != nil {
+			continue
+		}
+		instances, err := strconv.ParseInt(fields[1], 10, 64)
+		if err != nil {
+			continue
+		}
+		bytes, err := strconv.ParseInt(fields[2], 10, 64)
+		if err != nil {
+			continue
+		}
+		className := ""
+		if len(fields) > 3 {
+			className = strings.Join(fields[3:], " ")
+		}
+
+		h.Rows = append(h.Rows, HistogramRow{
+			Rank:      rank,
+			Instances: instances,
+			Bytes:     bytes,
+			ClassName: className,
+		})
+	}
+
+	if err := scanner.Err(); err != nil {
+		return h, fmt.Errorf("failed to parse heap histogram: %w", err)
+	}
+	return h, nil
+}
@@ -0,0 +1,10 @@
+// Package parse turns the raw textual output of jattach's helpers
+// (ThreadDump, InspectHeap, Properties, and "jcmd VM.flags") into typed Go
+// structures, so callers don't have to scrape HotSpot's human-oriented
+// output themselves.
+//
+// The parsers are tolerant of the minor formatting differences between
+// HotSpot and OpenJ9, and of extra fields future JDK releases might add to
+// a line they otherwise recognize: unrecognized trailing tokens are
+// ignored rather than treated as errors.
+package parse
@@ -0,0 +1,108 @@
+package parse
+
+import "testing"
+
+const sampleThreadDump = `"main" #1 prio=5 os_prio=0 tid=0x00007f nid=0x4a07 waiting on condition [0x00007f]
+   java.lang.Thread.State: TIMED_WAITING (sleeping)
+	at java.lang.Thread.sleep(Native Method)
+	at Foo.bar(Foo.java:10)
+
+"Thread-0" #12 daemon prio=5 os_prio=0 tid=0x00007f nid=0x4a08 waiting for monitor entry [0x00007f]
+   java.lang.Thread.State: BLOCKED (on object monitor)
+	at Bar.baz(Bar.java:20)
+	- waiting to lock <0x000000076ab62208> (a java.lang.Object)
+	- locked <0x000000076ab62218> (a java.lang.Object)
+
+"Thread-1" #13 daemon prio=5 os_prio=0 tid=0x00007f nid=0x4a09 waiting for monitor entry [0x00007f]
+   java.lang.Thread.State: BLOCKED (on object monitor)
+	at Bar.baz(Bar.java:25)
+	- waiting to lock <0x000000076ab62218> (a java.lang.Object)
+	- locked <0x000000076ab62208> (a java.lang.Object)
+
+Locked ownable synchronizers:
+	- None
+`
+
+func TestParseThreadDump(t *testing.T) {
+	dump, err := ParseThreadDump(sampleThreadDump)
+	if err != nil {
+		t.Fatalf("ParseThreadDump failed: %v", err)
+	}
+	if len(dump.Threads) != 3 {
+		t.Fatalf("expected 3 threads, got %d", len(dump.Threads))
+	}
+
+	main := dump.Threads[0]
+	if main.Name != "main" || main.NativeID != 0x4a07 || main.State != "TIMED_WAITING" {
+		t.Errorf("unexpected main thread: %+v", main)
+	}
+	if len(main.StackFrames) != 2 || main.StackFrames[1].Line != 10 {
+		t.Errorf("unexpected stack frames: %+v", main.StackFrames)
+	}
+
+	worker := dump.Threads[1]
+	if !worker.DaemonFlag {
+		t.Errorf("expected Thread-0 to be a daemon")
+	}
+}
+
+func TestFindDeadlocks(t *testing.T) {
+	dump, err := ParseThreadDump(sampleThreadDump)
+	if err != nil {
+		t.Fatalf("ParseThreadDump failed: %v", err)
+	}
+
+	deadlocks := dump.FindDeadlocks()
+	if len(deadlocks) != 1 {
+		t.Fatalf("expected 1 deadlock, got %d: %v", len(deadlocks), deadlocks)
+	}
+	if len(deadlocks[0].Threads) != 2 {
+		t.Errorf("expected a 2-thread cycle, got %v", deadlocks[0].Threads)
+	}
+}
+
+const sampleHistogram = ` num     #instances         #bytes  class name
+----------------------------------------------
+   1:          1234         887656  [B
+   2:           456          12345  java.lang.String
+Total                2000        900001
+`
+
+func TestParseHistogram(t *testing.T) {
+	h, err := ParseHistogram(sampleHistogram)
+	if err != nil {
+		t.Fatalf("ParseHistogram failed: %v", err)
+	}
+	if len(h.Rows) != 2 {
+		t.Fatalf("expected 2 rows, got %d", len(h.Rows))
+	}
+	if h.Rows[0].ClassName != "[B" || h.Rows[0].Instances != 1234 {
+		t.Errorf("unexpected first row: %+v", h.Rows[0])
+	}
+	if h.Total.Bytes != 900001 {
+		t.Errorf("unexpected total: %+v", h.Total)
+	}
+}
+
+func TestParseProperties(t *testing.T) {
+	props := ParseProperties("java.version = 17.0.1\njava.vendor = Eclipse Adoptium\n")
+	if props["java.version"] != "17.0.1" {
+		t.Errorf("unexpected java.version: %q", props["java.version"])
+	}
+	if props["java.vendor"] != "Eclipse Adoptium" {
+		t.Errorf("unexpected java.vendor: %q", props["java.vendor"])
+	}
+}
+
+func TestParseVMFlags(t *testing.T) {
+	flags := ParseVMFlags("-XX:+UseG1GC -XX:MaxHeapSize=2147483648 -XX:-UseCompressedOops")
+	if flags["UseG1GC"] != "true" {
+		t.Errorf("expected UseG1GC=true, got %q", flags["UseG1GC"])
+	}
+	if flags["MaxHeapSize"] != "2147483648" {
+		t.Errorf("expected MaxHeapSize=2147483648, got %q", flags["MaxHeapSize"])
+	}
+	if flags["UseCompressedOops"] != "false" {
+		t.Errorf("expected UseCompressedOops=false, got %q", flags["UseCompressedOops"])
+	}
+}
@@ -0,0 +1,51 @@
+package parse
+
+import (
+	"bufio"
+	"strings"
+)
+
+// ParseProperties parses the output of a Properties/AgentProperties command
+// ("key = value" per line, as HotSpot prints them) into a map. Lines that
+// don't contain a "=" are ignored.
+func ParseProperties(raw string) map[string]string {
+	props := make(map[string]string)
+
+	scanner := bufio.NewScanner(strings.NewReader(raw))
+	for scanner.Scan() {
+		line := scanner.Text()
+		idx := strings.Index(line, "=")
+		if idx < 0 {
+			continue
+		}
+		key := strings.TrimSpace(line[:idx])
+		value := strings.TrimSpace(line[idx+1:])
+		if key == "" {
+			continue
+		}
+		props[key] = value
+	}
+	return props
+}
+
+// ParseVMFlags parses the output of "jcmd VM.flags" ("-XX:FlagName=value" or
+// "-XX:+BooleanFlag" / "-XX:-BooleanFlag" per token) into a map from flag
+// name to its string value ("true"/"false" for boolean flags).
+func ParseVMFlags(raw string) map[string]string {
+	flags := make(map[string]string)
+
+	for _, token := range strings.Fields(raw) {
+		token = strings.TrimPrefix(token, "-XX:")
+		switch {
+		case strings.HasPrefix(token, "+"):
+			flags[token[1:]] = "true"
+		case strings.HasPrefix(token, "-"):
+			flags[token[1:]] = "false"
+		default:
+			if idx := strings.Index(token, "="); idx > 0 {
+				flags[token[:idx]] = token[idx+1:]
+			}
+		}
+	}
+	return flags
+}
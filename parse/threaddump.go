@@ -0,0 +1,176 @@
+package parse
+
+import (
+	"bufio"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// ThreadDump is the parsed form of a "threaddump" (jstack-equivalent)
+// response.
+type ThreadDump struct {
+	Threads []Thread
+}
+
+// Thread describes a single thread entry in a thread dump.
+type Thread struct {
+	Name       string
+	ID         int64 // HotSpot's internal "#<n>" thread id
+	NativeID   int64 // OS-level thread id ("nid=0x...")
+	State      string
+	Priority   int
+	DaemonFlag bool
+
+	StackFrames         []Frame
+	LockedSynchronizers []string
+
+	// waitingOn is the address of the monitor this thread is blocked on, if
+	// any (from a "- waiting to lock <addr>" frame annotation). It is used
+	// by FindDeadlocks to build the wait-for graph.
+	waitingOn string
+}
+
+// Frame is a single "at Class.method(File:Line)" stack frame, optionally
+// annotated with monitors it holds.
+type Frame struct {
+	Class  string
+	Method string
+	File   string
+	Line   int
+
+	// Locked holds the monitors this frame acquired, reported by HotSpot as
+	// "- locked <0x...> (a java.lang.Class)" lines directly below the frame.
+	Locked []MonitorRef
+}
+
+// MonitorRef identifies an object monitor by its HotSpot address and the
+// class of the object it guards.
+type MonitorRef struct {
+	Address   string
+	ClassName string
+}
+
+var (
+	threadHeaderRe = regexp.MustCompile(`^"(.*)"\s+(.*)$`)
+	threadIDRe     = regexp.MustCompile(`#(\d+)`)
+	nativeIDRe     = regexp.MustCompile(`nid=(?:0x)?([0-9a-fA-F]+)`)
+	priorityRe     = regexp.MustCompile(`prio=(\d+)`)
+	stateLineRe    = regexp.MustCompile(`^\s*java\.lang\.Thread\.State:\s*(\S+)`)
+	frameRe        = regexp.MustCompile(`^\s*at\s+([\w.$<>]+)\.([\w<>]+)\(([^():]+)(?::(\d+))?\)\s*$`)
+	lockedFrameRe  = regexp.MustCompile(`^\s*-\s+locked\s+<(0x[0-9a-fA-F]+)>\s+\(a\s+([^)]+)\)`)
+	waitingFrameRe = regexp.MustCompile(`^\s*-\s+waiting to lock\s+<(0x[0-9a-fA-F]+)>`)
+)
+
+// ParseThreadDump parses the output of a ThreadDump (jstack-equivalent)
+// command into a ThreadDump.
+func ParseThreadDump(raw string) (ThreadDump, error) {
+	var dump ThreadDump
+	var current *Thread
+	inSynchronizers := false
+
+	flush := func() {
+		if current != nil {
+			dump.Threads = append(dump.Threads, *current)
+			current = nil
+		}
+	}
+
+	scanner := bufio.NewScanner(strings.NewReader(raw))
+	scanner.Buffer(make([]byte, 0, 64*1024), 1<<20)
+	for scanner.Scan() {
+		line := scanner.Text()
+
+		if m := threadHeaderRe.FindStringSubmatch(line); m != nil {
+			flush()
+			inSynchronizers = false
+			t := Thread{Name: m[1]}
+			rest := m[2]
+
+			if id, ok := firstInt(threadIDRe, rest); ok {
+				t.ID = id
+			}
+			if nid, ok := firstHex(nativeIDRe, rest); ok {
+				t.NativeID = nid
+			}
+			if prio, ok := firstInt(priorityRe, rest); ok {
+				t.Priority = int(prio)
+			}
+			t.DaemonFlag = strings.Contains(rest, "daemon")
+
+			current = &t
+			continue
+		}
+
+		if current == nil {
+			continue
+		}
+
+		if m := stateLineRe.FindStringSubmatch(line); m != nil {
+			current.State = m[1]
+			continue
+		}
+
+		if strings.Contains(line, "Locked ownable synchronizers") {
+			inSynchronizers = true
+			continue
+		}
+
+		if inSynchronizers {
+			trimmed := strings.TrimSpace(line)
+			if trimmed == "" {
+				inSynchronizers = false
+				continue
+			}
+			if trimmed != "- None" && strings.HasPrefix(trimmed, "-") {
+				current.LockedSynchronizers = append(current.LockedSynchronizers, strings.TrimSpace(strings.TrimPrefix(trimmed, "-")))
+			}
+			continue
+		}
+
+		if m := frameRe.FindStringSubmatch(line); m != nil {
+			frame := Frame{Class: m[1], Method: m[2], File: m[3]}
+			if m[4] != "" {
+				frame.Line, _ = strconv.Atoi(m[4])
+			}
+			current.StackFrames = append(current.StackFrames, frame)
+			continue
+		}
+
+		if m := lockedFrameRe.FindStringSubmatch(line); m != nil && len(current.StackFrames) > 0 {
+			last := &current.StackFrames[len(current.StackFrames)-1]
+			last.Locked = append(last.Locked, MonitorRef{Address: m[1], ClassName: m[2]})
+			continue
+		}
+
+		if m := waitingFrameRe.FindStringSubmatch(line); m != nil {
+			current.waitingOn = m[1]
+			continue
+		}
+	}
+	flush()
+
+	if err := scanner.Err(); err != nil {
+		return dump, fmt.Errorf("failed to parse thread dump: %w", err)
+	}
+	return dump, nil
+}
+
+func firstInt(re *regexp.Regexp, s string) (int64, bool) {
+	m := re.FindStringSubmatch(s)
+	if m == nil {
+		return 0, false
+	}
+	v, err := strconv.ParseInt(m[1], 10, 64)
+	return v, err == nil
+}
+
+func firstHex(re *regexp.Regexp, s string) (int64, bool) {
+	m := re.FindStringSubmatch(s)
+	if m == nil {
+		return 0, false
+	}
+	v, err := strconv.ParseInt(m[1], 16, 64)
+	return v, err == nil
+}
@@ -0,0 +1,127 @@
+// Package profile gives typed, idiomatic access to the two profiling
+// stacks most jattach users reach for: async-profiler (via LoadAgent) and
+// the JVM's built-in Flight Recorder (via ExecuteJcmd). It builds the
+// command strings both tools expect (comma-separated for async-profiler, a
+// space-separated jcmd line for JFR) and turns JFR's error status lines into
+// errors; async-profiler failures surface only through LoadAgent's exit
+// code.
+package profile
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	jattach "github.com/vlsi/jattach/v2"
+)
+
+// Event selects what async-profiler samples.
+type Event string
+
+// Events supported by async-profiler.
+const (
+	EventCPU   Event = "cpu"
+	EventAlloc Event = "alloc"
+	EventLock  Event = "lock"
+	EventWall  Event = "wall"
+)
+
+// OutputFormat selects how async-profiler renders its collected samples.
+type OutputFormat string
+
+// Output formats supported by async-profiler.
+const (
+	Flamegraph OutputFormat = "flamegraph"
+	JFROutput  OutputFormat = "jfr"
+	Collapsed  OutputFormat = "collapsed"
+)
+
+// Options configures an async-profiler run.
+type Options struct {
+	Event    Event
+	Interval time.Duration
+	Threads  bool
+	Framebuf int // frame buffer size in bytes; 0 uses async-profiler's default
+	Output   OutputFormat
+	File     string // path async-profiler writes its result to; required
+}
+
+// asyncSession remembers what's needed to stop a profiling run that was
+// started against a given pid.
+type asyncSession struct {
+	agentPath string
+	file      string
+}
+
+var (
+	asyncMu       sync.Mutex
+	asyncSessions = make(map[int]asyncSession)
+)
+
+// StartAsyncProfiler loads async-profiler's native agent (agentPath, e.g.
+// "/opt/async-profiler/lib/libasyncProfiler.so") into pid and starts
+// profiling per opts. opts.File is required: it's where StopAsyncProfiler
+// will read the result from.
+func StartAsyncProfiler(pid int, agentPath string, opts Options) error {
+	if opts.File == "" {
+		return fmt.Errorf("profile: Options.File is required")
+	}
+
+	cmd := buildStartCommand(opts)
+	if err := jattach.LoadAgent(pid, agentPath, cmd, true); err != nil {
+		return fmt.Errorf("failed to start async-profiler on pid %d: %w", pid, err)
+	}
+
+	asyncMu.Lock()
+	asyncSessions[pid] = asyncSession{agentPath: agentPath, file: opts.File}
+	asyncMu.Unlock()
+	return nil
+}
+
+// StopAsyncProfiler stops the async-profiler run previously started on pid
+// with StartAsyncProfiler and returns the contents of its output file.
+func StopAsyncProfiler(pid int) ([]byte, error) {
+	asyncMu.Lock()
+	sess, ok := asyncSessions[pid]
+	if ok {
+		delete(asyncSessions, pid)
+	}
+	asyncMu.Unlock()
+
+	if !ok {
+		return nil, fmt.Errorf("profile: no async-profiler session started for pid %d", pid)
+	}
+
+	if err := jattach.LoadAgent(pid, sess.agentPath, "stop,file="+sess.file, true); err != nil {
+		return nil, fmt.Errorf("failed to stop async-profiler on pid %d: %w", pid, err)
+	}
+
+	return readFile(sess.file)
+}
+
+// buildStartCommand assembles async-profiler's comma-separated command
+// string, e.g. "start,event=cpu,interval=10000000,threads,flamegraph,file=/tmp/out.html".
+func buildStartCommand(opts Options) string {
+	parts := []string{"start"}
+
+	if opts.Event != "" {
+		parts = append(parts, "event="+string(opts.Event))
+	}
+	if opts.Interval > 0 {
+		parts = append(parts, "interval="+strconv.FormatInt(opts.Interval.Nanoseconds(), 10))
+	}
+	if opts.Threads {
+		parts = append(parts, "threads")
+	}
+	if opts.Framebuf > 0 {
+		parts = append(parts, "framebuf="+strconv.Itoa(opts.Framebuf))
+	}
+	if opts.Output != "" {
+		parts = append(parts, string(opts.Output))
+	}
+	parts = append(parts, "file="+opts.File)
+
+	return strings.Join(parts, ",")
+}
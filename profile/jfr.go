@@ -0,0 +1,117 @@
+package profile
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+
+	jattach "github.com/vlsi/jattach/v2"
+)
+
+// JFRConfig configures a Java Flight Recorder session.
+type JFRConfig struct {
+	Name     string // recording name; required, used to stop/dump it later
+	Settings string // "profile", "default", or a path to a .jfc file
+	MaxSize  string // e.g. "100M"; empty leaves the JVM default
+	MaxAge   string // e.g. "1h"; empty leaves the JVM default
+	Path     string // file JFR dumps the recording to; required
+}
+
+// jfrSession remembers what's needed to dump and stop a recording started
+// with StartJFR.
+type jfrSession struct {
+	pid  int
+	path string
+}
+
+var (
+	jfrMu       sync.Mutex
+	jfrSessions = make(map[string]jfrSession)
+)
+
+// StartJFR begins a Flight Recorder recording on pid per cfg via
+// jcmd JFR.start.
+func StartJFR(pid int, cfg JFRConfig) error {
+	if cfg.Name == "" {
+		return fmt.Errorf("profile: JFRConfig.Name is required")
+	}
+	if cfg.Path == "" {
+		return fmt.Errorf("profile: JFRConfig.Path is required")
+	}
+
+	args := []string{"JFR.start", "name=" + cfg.Name}
+	if cfg.Settings != "" {
+		args = append(args, "settings="+cfg.Settings)
+	}
+	if cfg.MaxSize != "" {
+		args = append(args, "maxsize="+cfg.MaxSize)
+	}
+	if cfg.MaxAge != "" {
+		args = append(args, "maxage="+cfg.MaxAge)
+	}
+
+	out, err := jattach.ExecuteJcmd(pid, strings.Join(args, " "))
+	if err != nil {
+		return fmt.Errorf("failed to start JFR recording %q on pid %d: %w", cfg.Name, pid, err)
+	}
+	if err := checkJcmdOutput(out); err != nil {
+		return fmt.Errorf("failed to start JFR recording %q on pid %d: %w", cfg.Name, pid, err)
+	}
+
+	jfrMu.Lock()
+	jfrSessions[cfg.Name] = jfrSession{pid: pid, path: cfg.Path}
+	jfrMu.Unlock()
+	return nil
+}
+
+// StopJFR dumps and stops the recording previously started with StartJFR
+// under name, returning the contents of its recording file.
+func StopJFR(pid int, name string) ([]byte, error) {
+	jfrMu.Lock()
+	sess, ok := jfrSessions[name]
+	if ok {
+		delete(jfrSessions, name)
+	}
+	jfrMu.Unlock()
+
+	if !ok {
+		return nil, fmt.Errorf("profile: no JFR recording named %q started for pid %d", name, pid)
+	}
+
+	out, err := jattach.ExecuteJcmd(pid, "JFR.stop name="+name+" filename="+sess.path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to stop JFR recording %q on pid %d: %w", name, pid, err)
+	}
+	if err := checkJcmdOutput(out); err != nil {
+		return nil, fmt.Errorf("failed to stop JFR recording %q on pid %d: %w", name, pid, err)
+	}
+
+	return readFile(sess.path)
+}
+
+// checkJcmdOutput flags the error lines jcmd JFR/async-profiler commands
+// report on their own stdout rather than through a process exit code (e.g.
+// "No such recording" or "Flight Recorder not started").
+func checkJcmdOutput(out string) error {
+	lower := strings.ToLower(out)
+	if strings.Contains(lower, "no such") ||
+		strings.Contains(lower, "could not start") ||
+		strings.Contains(lower, "not started") ||
+		strings.Contains(lower, "unrecognized") ||
+		strings.Contains(lower, "failed to") ||
+		strings.Contains(lower, "error") {
+		return fmt.Errorf("%s", strings.TrimSpace(out))
+	}
+	return nil
+}
+
+// readFile reads the file an async-profiler or JFR session wrote its
+// result to.
+func readFile(path string) ([]byte, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read profiling output %s: %w", path, err)
+	}
+	return data, nil
+}
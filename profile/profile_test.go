@@ -0,0 +1,62 @@
+package profile
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBuildStartCommand(t *testing.T) {
+	got := buildStartCommand(Options{
+		Event:    EventAlloc,
+		Interval: 10 * time.Millisecond,
+		Threads:  true,
+		Framebuf: 2097152,
+		Output:   Flamegraph,
+		File:     "/tmp/out.html",
+	})
+	want := "start,event=alloc,interval=10000000,threads,framebuf=2097152,flamegraph,file=/tmp/out.html"
+	if got != want {
+		t.Errorf("buildStartCommand() = %q, want %q", got, want)
+	}
+}
+
+func TestBuildStartCommand_Minimal(t *testing.T) {
+	got := buildStartCommand(Options{File: "/tmp/out.jfr"})
+	want := "start,file=/tmp/out.jfr"
+	if got != want {
+		t.Errorf("buildStartCommand() = %q, want %q", got, want)
+	}
+}
+
+func TestCheckJcmdOutput(t *testing.T) {
+	cases := []struct {
+		name    string
+		out     string
+		wantErr bool
+	}{
+		{"success", "Started recording 1.", false},
+		{"no such recording", "No such recording", true},
+		{"unrecognized command", "Unrecognized command", true},
+		{"generic error", "Dump failed: Error occurred", true},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := checkJcmdOutput(tc.out)
+			if (err != nil) != tc.wantErr {
+				t.Errorf("checkJcmdOutput(%q) error = %v, wantErr %v", tc.out, err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestStopAsyncProfiler_NoSession(t *testing.T) {
+	if _, err := StopAsyncProfiler(999999); err == nil {
+		t.Error("expected error for pid with no session")
+	}
+}
+
+func TestStopJFR_NoSession(t *testing.T) {
+	if _, err := StopJFR(999999, "nonexistent"); err == nil {
+		t.Error("expected error for name with no session")
+	}
+}
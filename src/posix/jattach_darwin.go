@@ -0,0 +1,41 @@
+//go:build darwin && (amd64 || arm64)
+// +build darwin
+// +build amd64 arm64
+
+package posix
+
+import (
+	"fmt"
+	"net"
+	"os"
+
+	"golang.org/x/sys/unix"
+)
+
+// verifyPeerCredentials checks that the process on the other end of conn is
+// running as the current (effective) user, using LOCAL_PEERCRED (the BSD
+// equivalent of getpeereid(3) on a Unix domain socket). This mirrors the
+// credential check the C jattach performs before trusting a response.
+func verifyPeerCredentials(conn *net.UnixConn) error {
+	raw, err := conn.SyscallConn()
+	if err != nil {
+		return fmt.Errorf("failed to inspect attach socket: %w", err)
+	}
+
+	var cred *unix.Xucred
+	var peerErr error
+	err = raw.Control(func(fd uintptr) {
+		cred, peerErr = unix.GetsockoptXucred(int(fd), unix.SOL_LOCAL, unix.LOCAL_PEERCRED)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to inspect attach socket: %w", err)
+	}
+	if peerErr != nil {
+		return fmt.Errorf("failed to read peer credentials: %w", peerErr)
+	}
+
+	if euid := os.Geteuid(); euid != 0 && int(cred.Uid) != euid {
+		return fmt.Errorf("refusing to trust attach socket owned by uid %d", cred.Uid)
+	}
+	return nil
+}
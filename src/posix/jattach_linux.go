@@ -0,0 +1,41 @@
+//go:build linux && (amd64 || arm64)
+// +build linux
+// +build amd64 arm64
+
+package posix
+
+import (
+	"fmt"
+	"net"
+	"os"
+
+	"golang.org/x/sys/unix"
+)
+
+// verifyPeerCredentials checks that the process on the other end of conn is
+// running as the current (effective) user, using SO_PEERCRED. This mirrors
+// the credential check the C jattach performs via getsockopt before trusting
+// a response.
+func verifyPeerCredentials(conn *net.UnixConn) error {
+	raw, err := conn.SyscallConn()
+	if err != nil {
+		return fmt.Errorf("failed to inspect attach socket: %w", err)
+	}
+
+	var ucred *unix.Ucred
+	var ucredErr error
+	err = raw.Control(func(fd uintptr) {
+		ucred, ucredErr = unix.GetsockoptUcred(int(fd), unix.SOL_SOCKET, unix.SO_PEERCRED)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to inspect attach socket: %w", err)
+	}
+	if ucredErr != nil {
+		return fmt.Errorf("failed to read peer credentials: %w", ucredErr)
+	}
+
+	if euid := os.Geteuid(); euid != 0 && int(ucred.Uid) != euid {
+		return fmt.Errorf("refusing to trust attach socket owned by uid %d", ucred.Uid)
+	}
+	return nil
+}
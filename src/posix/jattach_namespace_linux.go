@@ -0,0 +1,271 @@
+//go:build linux && (amd64 || arm64)
+// +build linux
+// +build amd64 arm64
+
+package posix
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"syscall"
+
+	"golang.org/x/sys/unix"
+)
+
+// NamespaceOptions configures CallJattachInNamespace's namespace-crossing
+// behavior. See the AttachOptions doc comment in the top-level jattach
+// package for details.
+type NamespaceOptions struct {
+	EnterMountNamespace bool
+}
+
+// CallJattachInNamespace performs the HotSpot Dynamic Attach handshake
+// against a process that may be running in a different PID and mount
+// namespace than the caller, such as a containerized JVM. hostPid is the
+// process's PID as seen by the caller.
+func CallJattachInNamespace(ctx context.Context, hostPid int, args []string, out io.Writer, opts NamespaceOptions) (int, error) {
+	if hostPid <= 0 {
+		return 1, fmt.Errorf("invalid PID: %d", hostPid)
+	}
+	if len(args) == 0 {
+		return 1, fmt.Errorf("no command specified")
+	}
+	if len(args) > 1+maxAttachArgs {
+		return 1, fmt.Errorf("too many arguments: jattach supports at most %d", maxAttachArgs)
+	}
+
+	if err := checkProcessExists(hostPid); err != nil {
+		return 1, err
+	}
+
+	nspid, err := resolveNamespacedPID(hostPid)
+	if err != nil {
+		return 1, fmt.Errorf("failed to resolve namespaced PID for %d: %w", hostPid, err)
+	}
+
+	restoreCreds, err := matchEffectiveCredentials(hostPid)
+	if err != nil {
+		return 1, err
+	}
+	defer restoreCreds()
+
+	if opts.EnterMountNamespace {
+		restoreNS, err := enterMountNamespace(hostPid)
+		if err != nil {
+			return 1, err
+		}
+		defer restoreNS()
+	}
+
+	rootDir := filepath.Join("/proc", strconv.Itoa(hostPid), "root")
+	sockPath := filepath.Join(rootDir, "tmp", fmt.Sprintf(".java_pid%d", nspid))
+
+	if _, err := os.Stat(sockPath); err != nil {
+		if err := triggerNamespacedAttach(hostPid, nspid); err != nil {
+			return 1, err
+		}
+		if err := waitForSocket(ctx, sockPath, attachTimeout); err != nil {
+			return 1, fmt.Errorf("JVM %d (namespaced pid %d) did not respond to attach request: %w", hostPid, nspid, err)
+		}
+	}
+
+	if err := checkSocketOwner(sockPath); err != nil {
+		return 1, err
+	}
+
+	conn, err := net.DialTimeout("unix", sockPath, attachTimeout)
+	if err != nil {
+		return 1, fmt.Errorf("failed to connect to %s: %w", sockPath, err)
+	}
+	defer conn.Close()
+
+	if ctx.Done() != nil {
+		stop := context.AfterFunc(ctx, func() { conn.Close() })
+		defer stop()
+	}
+
+	if err := verifyPeerCredentials(conn.(*net.UnixConn)); err != nil {
+		return 1, err
+	}
+
+	if err := writeRequest(conn, args); err != nil {
+		if ctx.Err() != nil {
+			return 1, ctx.Err()
+		}
+		return 1, fmt.Errorf("failed to send attach request: %w", err)
+	}
+
+	exitCode, err := readResponse(conn, out)
+	if err != nil && ctx.Err() != nil {
+		return exitCode, ctx.Err()
+	}
+	return exitCode, err
+}
+
+// resolveNamespacedPID returns the PID HotSpot itself sees, i.e. the one it
+// writes into ".attach_pid<nspid>" and ".java_pid<nspid>" filenames. This is
+// read from the innermost entry of /proc/<hostPid>/status' NSpid field; on
+// a process that isn't in a nested PID namespace, NSpid has a single entry
+// equal to hostPid.
+func resolveNamespacedPID(hostPid int) (int, error) {
+	f, err := os.Open(filepath.Join("/proc", strconv.Itoa(hostPid), "status"))
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if !strings.HasPrefix(line, "NSpid:") {
+			continue
+		}
+		fields := strings.Fields(strings.TrimPrefix(line, "NSpid:"))
+		if len(fields) == 0 {
+			break
+		}
+		return strconv.Atoi(fields[len(fields)-1])
+	}
+	if err := scanner.Err(); err != nil {
+		return 0, err
+	}
+	// No NSpid field (older kernel without PID namespace reporting): the
+	// process isn't namespaced from our point of view.
+	return hostPid, nil
+}
+
+// triggerNamespacedAttach asks the target JVM to start listening on its
+// attach socket. The trigger file is created through /proc/<hostPid>/cwd or
+// /proc/<hostPid>/root/tmp, which resolve into the target's mount namespace
+// even without joining it, named after the namespaced PID HotSpot expects.
+func triggerNamespacedAttach(hostPid, nspid int) error {
+	name := fmt.Sprintf(".attach_pid%d", nspid)
+
+	cwdPath := filepath.Join("/proc", strconv.Itoa(hostPid), "cwd", name)
+	triggerPath := cwdPath
+	f, err := os.OpenFile(cwdPath, os.O_CREATE|os.O_WRONLY, 0660)
+	if err != nil {
+		tmpPath := filepath.Join("/proc", strconv.Itoa(hostPid), "root", "tmp", name)
+		f, err = os.OpenFile(tmpPath, os.O_CREATE|os.O_WRONLY, 0660)
+		if err != nil {
+			return fmt.Errorf("failed to create attach trigger file: %w", err)
+		}
+		triggerPath = tmpPath
+	}
+	f.Close()
+	defer os.Remove(triggerPath)
+
+	if err := syscall.Kill(hostPid, syscall.SIGQUIT); err != nil {
+		return fmt.Errorf("failed to signal process %d: %w", hostPid, err)
+	}
+	return nil
+}
+
+// matchEffectiveCredentials switches the calling thread's filesystem
+// UID/GID to the target process's, so the attach trigger file we create is
+// owned by the same user as the JVM and passes HotSpot's ownership check.
+// It returns a function that restores the caller's original fsuid/fsgid.
+func matchEffectiveCredentials(hostPid int) (func(), error) {
+	uid, gid, err := readProcessCredentials(hostPid)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read credentials of process %d: %w", hostPid, err)
+	}
+
+	runtime.LockOSThread()
+
+	prevUid, err := unix.SetfsuidRetUid(uid)
+	if err != nil {
+		runtime.UnlockOSThread()
+		return nil, fmt.Errorf("failed to set fsuid for process %d: %w", hostPid, err)
+	}
+	prevGid, err := unix.SetfsgidRetGid(gid)
+	if err != nil {
+		unix.SetfsuidRetUid(prevUid)
+		runtime.UnlockOSThread()
+		return nil, fmt.Errorf("failed to set fsgid for process %d: %w", hostPid, err)
+	}
+
+	return func() {
+		unix.SetfsgidRetGid(prevGid)
+		unix.SetfsuidRetUid(prevUid)
+		runtime.UnlockOSThread()
+	}, nil
+}
+
+// readProcessCredentials reads the effective UID and GID of hostPid from
+// /proc/<hostPid>/status.
+func readProcessCredentials(hostPid int) (uid, gid int, err error) {
+	f, err := os.Open(filepath.Join("/proc", strconv.Itoa(hostPid), "status"))
+	if err != nil {
+		return 0, 0, err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case strings.HasPrefix(line, "Uid:"):
+			fields := strings.Fields(strings.TrimPrefix(line, "Uid:"))
+			if len(fields) < 2 {
+				return 0, 0, fmt.Errorf("unexpected Uid line: %q", line)
+			}
+			if uid, err = strconv.Atoi(fields[1]); err != nil {
+				return 0, 0, err
+			}
+		case strings.HasPrefix(line, "Gid:"):
+			fields := strings.Fields(strings.TrimPrefix(line, "Gid:"))
+			if len(fields) < 2 {
+				return 0, 0, fmt.Errorf("unexpected Gid line: %q", line)
+			}
+			if gid, err = strconv.Atoi(fields[1]); err != nil {
+				return 0, 0, err
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return 0, 0, err
+	}
+	return uid, gid, nil
+}
+
+// enterMountNamespace joins the target process's mount namespace on a
+// locked OS thread, returning a function that restores the caller's
+// original mount namespace.
+func enterMountNamespace(hostPid int) (func(), error) {
+	runtime.LockOSThread()
+
+	self, err := os.Open("/proc/self/ns/mnt")
+	if err != nil {
+		runtime.UnlockOSThread()
+		return nil, fmt.Errorf("failed to open own mount namespace: %w", err)
+	}
+
+	target, err := os.Open(filepath.Join("/proc", strconv.Itoa(hostPid), "ns", "mnt"))
+	if err != nil {
+		self.Close()
+		runtime.UnlockOSThread()
+		return nil, fmt.Errorf("failed to open mount namespace of process %d: %w", hostPid, err)
+	}
+	defer target.Close()
+
+	if err := unix.Setns(int(target.Fd()), unix.CLONE_NEWNS); err != nil {
+		self.Close()
+		runtime.UnlockOSThread()
+		return nil, fmt.Errorf("failed to enter mount namespace of process %d: %w", hostPid, err)
+	}
+
+	return func() {
+		unix.Setns(int(self.Fd()), unix.CLONE_NEWNS)
+		self.Close()
+		runtime.UnlockOSThread()
+	}, nil
+}
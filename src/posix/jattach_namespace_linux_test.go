@@ -0,0 +1,50 @@
+//go:build linux && (amd64 || arm64)
+// +build linux
+// +build amd64 arm64
+
+package posix
+
+import (
+	"os"
+	"testing"
+)
+
+func TestResolveNamespacedPID_Self(t *testing.T) {
+	// The test binary isn't in a nested PID namespace, so it should see
+	// its own PID back, whether via the NSpid field or the no-field
+	// fallback.
+	pid := os.Getpid()
+	got, err := resolveNamespacedPID(pid)
+	if err != nil {
+		t.Fatalf("resolveNamespacedPID(%d) returned error: %v", pid, err)
+	}
+	if got != pid {
+		t.Errorf("resolveNamespacedPID(%d) = %d, want %d", pid, got, pid)
+	}
+}
+
+func TestResolveNamespacedPID_NoSuchProcess(t *testing.T) {
+	if _, err := resolveNamespacedPID(-1); err == nil {
+		t.Error("expected error for nonexistent PID, got nil")
+	}
+}
+
+func TestReadProcessCredentials_Self(t *testing.T) {
+	pid := os.Getpid()
+	uid, gid, err := readProcessCredentials(pid)
+	if err != nil {
+		t.Fatalf("readProcessCredentials(%d) returned error: %v", pid, err)
+	}
+	if uid != os.Geteuid() {
+		t.Errorf("readProcessCredentials(%d) uid = %d, want %d", pid, uid, os.Geteuid())
+	}
+	if gid != os.Getegid() {
+		t.Errorf("readProcessCredentials(%d) gid = %d, want %d", pid, gid, os.Getegid())
+	}
+}
+
+func TestReadProcessCredentials_NoSuchProcess(t *testing.T) {
+	if _, _, err := readProcessCredentials(-1); err == nil {
+		t.Error("expected error for nonexistent PID, got nil")
+	}
+}
@@ -2,53 +2,243 @@
 // +build linux darwin
 // +build amd64 arm64
 
+// Package posix implements the HotSpot Dynamic Attach protocol natively in
+// Go for Linux and macOS, without relying on CGo or the upstream jattach C
+// sources. It speaks the same wire protocol as the C tool: a Unix domain
+// socket opened by the target JVM under /tmp (or $TMPDIR on macOS),
+// triggered by dropping a ".attach_pid<pid>" file next to the process and
+// sending it SIGQUIT.
 package posix
 
-/*
-#cgo CFLAGS: -I${SRCDIR} -O3
-#cgo linux CFLAGS: -D_GNU_SOURCE
-
-#include <stdlib.h>
-#include "psutil.h"
-
-// Forward declaration of the jattach function
-extern int jattach(int pid, int argc, char** argv, int print_output);
-*/
-import "C"
 import (
+	"bufio"
+	"context"
 	"fmt"
-	"unsafe"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+const (
+	attachTimeout  = 5 * time.Second
+	attachPollStep = 20 * time.Millisecond
+	maxAttachArgs  = 4
 )
 
-// CallJattach is the low-level CGo wrapper for the jattach C function.
-// It handles C string conversion and memory management.
-// Returns the exit code from the jattach function.
-func CallJattach(pid int, args []string, printOutput bool) (int, error) {
+// CallJattach performs the HotSpot Dynamic Attach handshake against pid and
+// runs the command described by args (the command name followed by up to
+// four arguments). The JVM's response stream is copied to out as it
+// arrives; pass io.Discard to ignore it. ctx governs the whole handshake:
+// once it is done, the attach socket is closed and any in-flight read or
+// write unblocks with ctx.Err(). It returns the exit code reported by the
+// JVM.
+func CallJattach(ctx context.Context, pid int, args []string, out io.Writer) (int, error) {
 	if pid <= 0 {
 		return 1, fmt.Errorf("invalid PID: %d", pid)
 	}
-
 	if len(args) == 0 {
 		return 1, fmt.Errorf("no command specified")
 	}
+	if len(args) > 1+maxAttachArgs {
+		return 1, fmt.Errorf("too many arguments: jattach supports at most %d", maxAttachArgs)
+	}
+
+	if err := checkProcessExists(pid); err != nil {
+		return 1, err
+	}
 
-	// Convert Go strings to C strings
-	argc := C.int(len(args))
-	argv := make([]*C.char, len(args))
+	sockPath := socketPath(pid)
 
-	for i, arg := range args {
-		argv[i] = C.CString(arg)
-		defer C.free(unsafe.Pointer(argv[i]))
+	if _, err := os.Stat(sockPath); err != nil {
+		if err := triggerAttach(pid); err != nil {
+			return 1, err
+		}
+		if err := waitForSocket(ctx, sockPath, attachTimeout); err != nil {
+			return 1, fmt.Errorf("JVM %d did not respond to attach request: %w", pid, err)
+		}
 	}
 
-	// Determine print_output flag
-	printOutputInt := C.int(0)
-	if printOutput {
-		printOutputInt = C.int(1)
+	if err := checkSocketOwner(sockPath); err != nil {
+		return 1, err
 	}
 
-	// Call the C function
-	ret := C.jattach(C.int(pid), argc, &argv[0], printOutputInt)
+	conn, err := net.DialTimeout("unix", sockPath, attachTimeout)
+	if err != nil {
+		return 1, fmt.Errorf("failed to connect to %s: %w", sockPath, err)
+	}
+	defer conn.Close()
+
+	if ctx.Done() != nil {
+		stop := context.AfterFunc(ctx, func() { conn.Close() })
+		defer stop()
+	}
+
+	if err := verifyPeerCredentials(conn.(*net.UnixConn)); err != nil {
+		return 1, err
+	}
+
+	if err := writeRequest(conn, args); err != nil {
+		if ctx.Err() != nil {
+			return 1, ctx.Err()
+		}
+		return 1, fmt.Errorf("failed to send attach request: %w", err)
+	}
+
+	exitCode, err := readResponse(conn, out)
+	if err != nil && ctx.Err() != nil {
+		return exitCode, ctx.Err()
+	}
+	return exitCode, err
+}
+
+// socketPath returns the path of the Unix domain socket that the target JVM
+// creates in response to a successful attach request.
+func socketPath(pid int) string {
+	return filepath.Join(tmpDir(), fmt.Sprintf(".java_pid%d", pid))
+}
+
+// tmpDir returns the directory HotSpot uses for its attach socket: /tmp on
+// Linux, and $TMPDIR (falling back to /tmp) on macOS.
+func tmpDir() string {
+	if runtime.GOOS == "darwin" {
+		if dir := os.Getenv("TMPDIR"); dir != "" {
+			return strings.TrimRight(dir, "/")
+		}
+	}
+	return "/tmp"
+}
+
+// triggerAttach asks the target JVM to start listening on its attach socket
+// by dropping a trigger file next to it and sending SIGQUIT.
+func triggerAttach(pid int) error {
+	triggerPath, err := createTriggerFile(pid)
+	if err != nil {
+		return fmt.Errorf("failed to create attach trigger file: %w", err)
+	}
+	defer os.Remove(triggerPath)
+
+	if err := syscall.Kill(pid, syscall.SIGQUIT); err != nil {
+		return fmt.Errorf("failed to signal process %d: %w", pid, err)
+	}
+	return nil
+}
+
+// createTriggerFile creates the ".attach_pid<pid>" file HotSpot polls for
+// after a SIGQUIT. On Linux it is created in the target's own working
+// directory (via /proc/<pid>/cwd) so the check also succeeds when the
+// caller and target disagree on their filesystem view, falling back to
+// /tmp when /proc is unavailable or the directory isn't writable. macOS has
+// no /proc, so /tmp is used directly.
+func createTriggerFile(pid int) (string, error) {
+	name := fmt.Sprintf(".attach_pid%d", pid)
+
+	if runtime.GOOS == "linux" {
+		cwdPath := filepath.Join("/proc", strconv.Itoa(pid), "cwd", name)
+		if f, err := os.OpenFile(cwdPath, os.O_CREATE|os.O_WRONLY, 0660); err == nil {
+			f.Close()
+			return cwdPath, nil
+		}
+	}
+
+	tmpPath := filepath.Join("/tmp", name)
+	f, err := os.OpenFile(tmpPath, os.O_CREATE|os.O_WRONLY, 0660)
+	if err != nil {
+		return "", err
+	}
+	f.Close()
+	return tmpPath, nil
+}
+
+// waitForSocket polls for the attach socket to appear, mirroring the retry
+// loop of the original jattach.c: the JVM's signal handler only gets a
+// chance to run between safepoints, so this can legitimately take a while
+// under load. It returns early with ctx.Err() if ctx is done first.
+func waitForSocket(ctx context.Context, path string, timeout time.Duration) error {
+	deadline := time.Now().Add(timeout)
+	for time.Now().Before(deadline) {
+		if _, err := os.Stat(path); err == nil {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(attachPollStep):
+		}
+	}
+	return fmt.Errorf("timed out after %s waiting for %s", timeout, path)
+}
+
+// checkSocketOwner rejects sockets not owned by the current user, matching
+// the ownership check the C jattach performs before it will connect.
+func checkSocketOwner(path string) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return fmt.Errorf("failed to stat %s: %w", path, err)
+	}
+	stat, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return nil
+	}
+	if uid := os.Geteuid(); uid != 0 && int(stat.Uid) != uid {
+		return fmt.Errorf("socket %s is not owned by the current user", path)
+	}
+	return nil
+}
+
+// checkProcessExists verifies pid refers to a process we can signal before
+// we bother creating trigger files.
+func checkProcessExists(pid int) error {
+	if err := syscall.Kill(pid, 0); err != nil {
+		return fmt.Errorf("process %d does not exist or is not accessible: %w", pid, err)
+	}
+	return nil
+}
+
+// writeRequest sends the attach command in HotSpot's wire format:
+// "1\0<cmd>\0<arg1>\0<arg2>\0<arg3>\0<arg4>\0", with missing trailing
+// arguments sent as empty strings.
+func writeRequest(w io.Writer, args []string) error {
+	fields := make([]string, 0, 1+maxAttachArgs)
+	fields = append(fields, "1")
+	fields = append(fields, args...)
+	for len(fields) < 1+maxAttachArgs+1 {
+		fields = append(fields, "")
+	}
+
+	var buf strings.Builder
+	for _, f := range fields {
+		buf.WriteString(f)
+		buf.WriteByte(0)
+	}
+	_, err := w.Write([]byte(buf.String()))
+	return err
+}
+
+// readResponse reads the JVM's reply: a decimal exit-code line, then the
+// command's output streamed until the JVM closes its end of the socket. The
+// output is copied to out as it is received, rather than buffered in full.
+func readResponse(r io.Reader, out io.Writer) (int, error) {
+	br := bufio.NewReader(r)
+
+	line, err := br.ReadString('\n')
+	if err != nil && line == "" {
+		return 1, fmt.Errorf("failed to read attach response: %w", err)
+	}
+
+	exitCode, convErr := strconv.Atoi(strings.TrimSpace(line))
+	if convErr != nil {
+		exitCode = 0
+	}
+
+	if _, err := io.Copy(out, br); err != nil {
+		return exitCode, fmt.Errorf("failed to read command output: %w", err)
+	}
 
-	return int(ret), nil
+	return exitCode, nil
 }
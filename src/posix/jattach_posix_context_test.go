@@ -0,0 +1,61 @@
+//go:build (linux || darwin) && (amd64 || arm64)
+// +build linux darwin
+// +build amd64 arm64
+
+package posix
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"os"
+	"testing"
+	"time"
+)
+
+// TestCallJattach_ContextCancellation exercises a CallJattach against a
+// stub "JVM" that accepts the attach connection but never responds,
+// checking that a short-deadline ctx unblocks the pending read almost
+// immediately instead of waiting out attachTimeout.
+func TestCallJattach_ContextCancellation(t *testing.T) {
+	pid := os.Getpid()
+	sockPath := socketPath(pid)
+	os.Remove(sockPath)
+
+	ln, err := net.Listen("unix", sockPath)
+	if err != nil {
+		t.Fatalf("failed to listen on %s: %v", sockPath, err)
+	}
+	defer ln.Close()
+	defer os.Remove(sockPath)
+
+	accepted := make(chan net.Conn, 1)
+	go func() {
+		conn, err := ln.Accept()
+		if err == nil {
+			accepted <- conn
+		}
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, err = CallJattach(ctx, pid, []string{"properties"}, io.Discard)
+	elapsed := time.Since(start)
+
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("CallJattach returned %v, want context.DeadlineExceeded", err)
+	}
+	if elapsed > attachTimeout {
+		t.Errorf("CallJattach took %s, expected ctx to unblock it well before attachTimeout (%s)", elapsed, attachTimeout)
+	}
+
+	select {
+	case conn := <-accepted:
+		conn.Close()
+	case <-time.After(time.Second):
+		t.Error("stub JVM never accepted the attach connection")
+	}
+}
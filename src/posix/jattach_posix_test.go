@@ -0,0 +1,70 @@
+//go:build (linux || darwin) && (amd64 || arm64)
+// +build linux darwin
+// +build amd64 arm64
+
+package posix
+
+import (
+	"bytes"
+	"io"
+	"testing"
+)
+
+func TestWriteRequest(t *testing.T) {
+	cases := []struct {
+		name string
+		args []string
+		want string
+	}{
+		{"no args", []string{"properties"}, "1\x00properties\x00\x00\x00\x00\x00"},
+		{"one arg", []string{"threaddump", "-l"}, "1\x00threaddump\x00-l\x00\x00\x00\x00"},
+		{"max args", []string{"load", "a.jar", "true", "opts", "extra"}, "1\x00load\x00a.jar\x00true\x00opts\x00extra\x00"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := writeRequest(&buf, tc.args); err != nil {
+				t.Fatalf("writeRequest failed: %v", err)
+			}
+			if got := buf.String(); got != tc.want {
+				t.Errorf("writeRequest(%v) wrote %q, want %q", tc.args, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestReadResponse(t *testing.T) {
+	cases := []struct {
+		name         string
+		input        string
+		wantExitCode int
+		wantOutput   string
+	}{
+		{"normal", "0\nhello world", 0, "hello world"},
+		{"nonzero exit", "1\nsomething failed", 1, "something failed"},
+		{"malformed status line", "not-a-number\nsome output", 0, "some output"},
+		{"no trailing output", "0\n", 0, ""},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			var out bytes.Buffer
+			exitCode, err := readResponse(bytes.NewBufferString(tc.input), &out)
+			if err != nil {
+				t.Fatalf("readResponse failed: %v", err)
+			}
+			if exitCode != tc.wantExitCode {
+				t.Errorf("exit code = %d, want %d", exitCode, tc.wantExitCode)
+			}
+			if out.String() != tc.wantOutput {
+				t.Errorf("output = %q, want %q", out.String(), tc.wantOutput)
+			}
+		})
+	}
+}
+
+func TestReadResponse_NoStatusLine(t *testing.T) {
+	_, err := readResponse(bytes.NewBufferString(""), io.Discard)
+	if err == nil {
+		t.Error("expected error reading an empty response, got nil")
+	}
+}
@@ -2,52 +2,244 @@
 // +build windows
 // +build amd64 arm64 386
 
+// Package windows implements the HotSpot Dynamic Attach protocol natively in
+// Go for Windows, without relying on CGo or the upstream jattach C sources.
+// Unlike the POSIX implementation, there is no signal to trigger the
+// attach listener: instead, a remote thread is injected into the target
+// process via ntdll's debug-breakin helper, which the JVM's unhandled
+// exception filter recognizes as an attach request and responds to by
+// opening a named pipe.
 package windows
 
-/*
-#cgo CFLAGS: -I${SRCDIR} -O2 -D_CRT_SECURE_NO_WARNINGS
-#cgo LDFLAGS: -ladvapi32
-
-#include <stdlib.h>
-
-// Forward declaration of the jattach function
-extern int jattach(int pid, int argc, char** argv, int print_output);
-*/
-import "C"
 import (
+	"bufio"
+	"context"
 	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
 	"unsafe"
+
+	"golang.org/x/sys/windows"
 )
 
-// CallJattach is the low-level CGo wrapper for the jattach C function (Windows implementation).
-// It handles C string conversion and memory management.
-// Returns the exit code from the jattach function.
-func CallJattach(pid int, args []string, printOutput bool) (int, error) {
+// CreateRemoteThread isn't wrapped by golang.org/x/sys/windows, so it's
+// resolved by hand the same way x/sys/windows itself resolves kernel32
+// entry points it doesn't already bind.
+var (
+	modkernel32            = windows.NewLazySystemDLL("kernel32.dll")
+	procCreateRemoteThread = modkernel32.NewProc("CreateRemoteThread")
+)
+
+// createRemoteThread creates a thread that runs in the virtual address
+// space of another process, starting at startAddr.
+func createRemoteThread(hProcess windows.Handle, stackSize uint32, startAddr uintptr, parameter uintptr, creationFlags uint32) (windows.Handle, uint32, error) {
+	var threadID uint32
+	r1, _, err := procCreateRemoteThread.Call(
+		uintptr(hProcess),
+		0, // lpThreadAttributes
+		uintptr(stackSize),
+		startAddr,
+		parameter,
+		uintptr(creationFlags),
+		uintptr(unsafe.Pointer(&threadID)),
+	)
+	if r1 == 0 {
+		return 0, 0, err
+	}
+	return windows.Handle(r1), threadID, nil
+}
+
+const (
+	attachTimeout  = 5 * time.Second
+	attachPollStep = 20 * time.Millisecond
+	maxAttachArgs  = 4
+)
+
+// CallJattach performs the HotSpot Dynamic Attach handshake against pid and
+// runs the command described by args (the command name followed by up to
+// four arguments). The JVM's response stream is copied to out as it
+// arrives; pass io.Discard to ignore it. ctx governs the whole handshake:
+// once it is done, the attach pipe is closed and any in-flight read or
+// write unblocks with ctx.Err(). It returns the exit code reported by the
+// JVM.
+func CallJattach(ctx context.Context, pid int, args []string, out io.Writer) (int, error) {
 	if pid <= 0 {
 		return 1, fmt.Errorf("invalid PID: %d", pid)
 	}
-
 	if len(args) == 0 {
 		return 1, fmt.Errorf("no command specified")
 	}
+	if len(args) > 1+maxAttachArgs {
+		return 1, fmt.Errorf("too many arguments: jattach supports at most %d", maxAttachArgs)
+	}
+
+	pipeName := pipePath(pid)
+
+	if _, err := os.Stat(pipeName); err != nil {
+		if err := triggerAttach(pid); err != nil {
+			return 1, err
+		}
+		if err := waitForPipe(ctx, pipeName, attachTimeout); err != nil {
+			return 1, fmt.Errorf("JVM %d did not respond to attach request: %w", pid, err)
+		}
+	}
+
+	handle, err := windows.CreateFile(
+		windows.StringToUTF16Ptr(pipeName),
+		windows.GENERIC_READ|windows.GENERIC_WRITE,
+		0,
+		nil,
+		windows.OPEN_EXISTING,
+		0,
+		0,
+	)
+	if err != nil {
+		return 1, fmt.Errorf("failed to connect to %s: %w", pipeName, err)
+	}
+	defer windows.CloseHandle(handle)
+
+	if ctx.Done() != nil {
+		stop := context.AfterFunc(ctx, func() { windows.CloseHandle(handle) })
+		defer stop()
+	}
+
+	if err := writeRequest(handle, args); err != nil {
+		if ctx.Err() != nil {
+			return 1, ctx.Err()
+		}
+		return 1, fmt.Errorf("failed to send attach request: %w", err)
+	}
+
+	exitCode, err := readResponse(handle, out)
+	if err != nil && ctx.Err() != nil {
+		return exitCode, ctx.Err()
+	}
+	return exitCode, err
+}
+
+// pipePath returns the named pipe the target JVM opens in response to a
+// successful attach request.
+func pipePath(pid int) string {
+	return fmt.Sprintf(`\\.\pipe\javatool%d`, pid)
+}
+
+// triggerAttach injects a remote thread into the target process that calls
+// ntdll's debug-breakin helper. HotSpot's unhandled exception filter treats
+// the resulting breakpoint exception as an attach trigger, the same way its
+// POSIX signal handler treats SIGQUIT.
+func triggerAttach(pid int) error {
+	hProcess, err := windows.OpenProcess(
+		windows.PROCESS_CREATE_THREAD|windows.PROCESS_QUERY_INFORMATION|windows.PROCESS_VM_OPERATION|windows.PROCESS_VM_WRITE|windows.PROCESS_VM_READ,
+		false,
+		uint32(pid),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to open process %d: %w", pid, err)
+	}
+	defer windows.CloseHandle(hProcess)
+
+	ntdll, err := windows.LoadDLL("ntdll.dll")
+	if err != nil {
+		return fmt.Errorf("failed to load ntdll.dll: %w", err)
+	}
+	breakin, err := ntdll.FindProc("DbgUiRemoteBreakin")
+	if err != nil {
+		return fmt.Errorf("failed to resolve DbgUiRemoteBreakin: %w", err)
+	}
+
+	hThread, _, err := createRemoteThread(hProcess, 0, breakin.Addr(), 0, 0)
+	if err != nil {
+		return fmt.Errorf("failed to create remote thread in process %d: %w", pid, err)
+	}
+	defer windows.CloseHandle(hThread)
+
+	if _, err := windows.WaitForSingleObject(hThread, uint32(attachTimeout.Milliseconds())); err != nil {
+		return fmt.Errorf("remote attach thread in process %d did not complete: %w", pid, err)
+	}
+	return nil
+}
+
+// waitForPipe polls for the attach pipe to appear. It returns early with
+// ctx.Err() if ctx is done first.
+func waitForPipe(ctx context.Context, path string, timeout time.Duration) error {
+	deadline := time.Now().Add(timeout)
+	for time.Now().Before(deadline) {
+		if _, err := os.Stat(path); err == nil {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(attachPollStep):
+		}
+	}
+	return fmt.Errorf("timed out after %s waiting for %s", timeout, path)
+}
+
+// writeRequest sends the attach command in HotSpot's wire format:
+// "1\0<cmd>\0<arg1>\0<arg2>\0<arg3>\0<arg4>\0", with missing trailing
+// arguments sent as empty strings.
+func writeRequest(handle windows.Handle, args []string) error {
+	fields := make([]string, 0, 1+maxAttachArgs)
+	fields = append(fields, "1")
+	fields = append(fields, args...)
+	for len(fields) < 1+maxAttachArgs+1 {
+		fields = append(fields, "")
+	}
 
-	// Convert Go strings to C strings
-	argc := C.int(len(args))
-	argv := make([]*C.char, len(args))
+	var buf strings.Builder
+	for _, f := range fields {
+		buf.WriteString(f)
+		buf.WriteByte(0)
+	}
 
-	for i, arg := range args {
-		argv[i] = C.CString(arg)
-		defer C.free(unsafe.Pointer(argv[i]))
+	data := []byte(buf.String())
+	var written uint32
+	return windows.WriteFile(handle, data, &written, nil)
+}
+
+// readResponse reads the JVM's reply: a decimal exit-code line, then the
+// command's output streamed until the pipe is closed. The output is copied
+// to out as it is received, rather than buffered in full.
+func readResponse(handle windows.Handle, out io.Writer) (int, error) {
+	br := bufio.NewReader(&pipeReader{handle: handle})
+
+	line, err := br.ReadString('\n')
+	if err != nil && line == "" {
+		return 1, fmt.Errorf("failed to read attach response: %w", err)
 	}
 
-	// Determine print_output flag
-	printOutputInt := C.int(0)
-	if printOutput {
-		printOutputInt = C.int(1)
+	exitCode, convErr := strconv.Atoi(strings.TrimSpace(line))
+	if convErr != nil {
+		exitCode = 0
 	}
 
-	// Call the C function
-	ret := C.jattach(C.int(pid), argc, &argv[0], printOutputInt)
+	if _, err := io.Copy(out, br); err != nil {
+		return exitCode, fmt.Errorf("failed to read command output: %w", err)
+	}
 
-	return int(ret), nil
+	return exitCode, nil
+}
+
+// pipeReader adapts a raw pipe handle to io.Reader.
+type pipeReader struct {
+	handle windows.Handle
+}
+
+func (p *pipeReader) Read(b []byte) (int, error) {
+	var n uint32
+	err := windows.ReadFile(p.handle, b, &n, nil)
+	if err != nil {
+		if err == windows.ERROR_BROKEN_PIPE {
+			return int(n), io.EOF
+		}
+		return int(n), err
+	}
+	if n == 0 {
+		return 0, io.EOF
+	}
+	return int(n), nil
 }